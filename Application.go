@@ -9,6 +9,9 @@ import (
     "runtime"
     "strings"
     "sync"
+    "time"
+
+    "github.com/pinguo/pgo/Util"
 )
 
 // Application the pgo app,
@@ -23,6 +26,8 @@ import (
 // runtimePath: "@app/runtime"
 // publicPath:  "@app/public"
 // viewPath:    "@viewPath"
+// reloadInterval: "0s"
+// configProviders: []
 // container:   {}
 // server:      {}
 // components:  {}
@@ -38,14 +43,21 @@ type Application struct {
     config     *Config
     container  *Container
     server     *Server
+    events     *EventBus
     components map[string]interface{}
+    loaders    map[string]*sync.Mutex // lock of component loading
+    order      []string               // id of components in order of loading
     lock       sync.RWMutex
     router     *Router
     log        *Log
     status     *Status
     i18n       *I18n
     view       *View
+    errHandler *ErrorHandler
     stopBefore *StopBefore // 服务停止前执行 [{"obj":"func"}]
+
+    checkConfig   bool   // check config and exit
+    explainConfig string // explain config key and exit
 }
 
 func (app *Application) getBasePath(exeDir string) string {
@@ -68,8 +80,11 @@ func (app *Application) Construct() {
     app.basePath = app.getBasePath(exeDir)
     app.config = &Config{}
     app.container = &Container{}
+    app.container.Construct()
+    app.events = &EventBus{}
     app.server = &Server{}
     app.components = make(map[string]interface{})
+    app.loaders = make(map[string]*sync.Mutex)
     app.stopBefore = &StopBefore{}
 }
 
@@ -77,8 +92,15 @@ func (app *Application) Init() {
     env := flag.String("env", "", "set running env, eg. --env online")
     cmd := flag.String("cmd", "", "set running cmd, eg. --cmd /foo/bar")
     base := flag.String("base", "", "set base path, eg. --base /base/path")
+    check := flag.Bool("check-config", false, "check config of all envs and exit")
+    explain := flag.String("explain-config", "", "print sources of config key and exit, eg. --explain-config app.server")
+    sets := overlayFlags{}
+    flag.Var(&sets, "set", "override config key, repeatable, eg. --set app.server.httpAddr=:9000")
     flag.Parse()
 
+    app.checkConfig = *check
+    app.explainConfig = *explain
+
     // overwrite running env
     if len(*env) > 0 {
         app.env = *env
@@ -100,16 +122,49 @@ func (app *Application) Init() {
     SetAlias("@app", app.basePath)
     SetAlias("@pgo", strings.TrimPrefix(pkgPath, VendorPrefix))
 
+    // initialize event bus
+    ConstructAndInit(app.events, nil)
+
     // initialize config object
     ConstructAndInit(app.config, nil)
 
-    // initialize container object
+    // override config keys by --set flags
+    for _, v := range sets {
+        pos := strings.IndexByte(v, '=')
+        app.config.AddOverlay(v[:pos], v[pos+1:], "flag:--set "+v[:pos])
+    }
+
+    // configure container object, container is constructed
+    // with app for classes are bound before app initialized.
     cntConf, _ := app.config.Get("app.container").(map[string]interface{})
-    ConstructAndInit(app.container, cntConf)
+    Configure(app.container, cntConf)
+    app.container.SetStrict(app.container.strict || app.checkConfig)
+
+    // add remote config providers, providers are added
+    // before server and components are configured.
+    providers, _ := app.config.Get("app.configProviders").([]interface{})
+    for i, v := range providers {
+        conf, ok := v.(map[string]interface{})
+        if !ok {
+            panic(fmt.Sprintf("invalid config provider: %v", v))
+        }
+
+        cacheFile := fmt.Sprintf("@app/runtime/config.remote%d.json", i)
+        provConf := make(map[string]interface{})
+        for key, val := range conf {
+            if key == "cacheFile" {
+                cacheFile = Util.ToString(val)
+            } else {
+                provConf[key] = val
+            }
+        }
+
+        app.config.AddProvider(CreateObject(provConf).(IConfigProvider), cacheFile)
+    }
 
     // initialize server object
     svrConf, _ := app.config.Get("app.server").(map[string]interface{})
-    ConstructAndInit(app.server, svrConf)
+    app.configure("app.server", func() { ConstructAndInit(app.server, svrConf) })
 
     // overwrite app name
     if name := app.config.GetString("app.name", ""); len(name) > 0 {
@@ -136,6 +191,15 @@ func (app *Application) Init() {
     app.viewPath, _ = filepath.Abs(GetAlias(viewPath))
     SetAlias("@view", app.viewPath)
 
+    // watch config files for hot reload
+    if v := app.config.GetString("app.reloadInterval", ""); len(v) > 0 {
+        if interval, e := time.ParseDuration(v); e != nil {
+            panic(fmt.Sprintf("failed to parse reloadInterval, val:%s, err:%s", v, e))
+        } else {
+            app.config.StartWatch(interval)
+        }
+    }
+
     // set core components
     for id, class := range app.coreComponents() {
         key := fmt.Sprintf("app.components.%s.class", id)
@@ -195,6 +259,11 @@ func (app *Application) GetContainer() *Container {
     return app.container
 }
 
+// GetEventBus get event bus
+func (app *Application) GetEventBus() *EventBus {
+    return app.events
+}
+
 // GetServer get server component
 func (app *Application) GetServer() *Server {
     return app.server
@@ -245,6 +314,15 @@ func (app *Application) GetView() *View {
     return app.view
 }
 
+// GetErrorHandler get error handler component
+func (app *Application) GetErrorHandler() *ErrorHandler {
+    if app.errHandler == nil {
+        app.errHandler = app.Get("errorHandler").(*ErrorHandler)
+    }
+
+    return app.errHandler
+}
+
 // GetStopBefore get stopBefore component
 func (app *Application) GetStopBefore() *StopBefore {
     return app.stopBefore
@@ -252,31 +330,103 @@ func (app *Application) GetStopBefore() *StopBefore {
 
 // Get get component by id
 func (app *Application) Get(id string) interface{} {
-    if _, ok := app.components[id]; !ok {
-        app.loadComponent(id)
-    }
-
     app.lock.RLock()
-    defer app.lock.RUnlock()
+    component, ok := app.components[id]
+    app.lock.RUnlock()
+
+    if !ok {
+        component = app.loadComponent(id)
+    }
 
-    return app.components[id]
+    return component
 }
 
-func (app *Application) loadComponent(id string) {
+// loadComponent create component, components it depends on are
+// loaded first, component is started if it implements IStarter,
+// app lock is not held while creating, so dependencies can be
+// injected or got in Construct and Init of component, panic
+// if dependencies are circular instead of waiting forever.
+func (app *Application) loadComponent(id string) interface{} {
+    if cycle := app.dependsCycle(id, nil); cycle != nil {
+        panic(circularError(cycle).Error())
+    }
+
     app.lock.Lock()
-    defer app.lock.Unlock()
+    loader, ok := app.loaders[id]
+    if !ok {
+        loader = &sync.Mutex{}
+        app.loaders[id] = loader
+    }
+    app.lock.Unlock()
+
+    loader.Lock()
+    defer loader.Unlock()
 
     // avoid repeated loading
-    if _, ok := app.components[id]; ok {
-        return
+    app.lock.RLock()
+    component, ok := app.components[id]
+    app.lock.RUnlock()
+    if ok {
+        return component
     }
 
-    conf := app.config.Get("app.components." + id)
-    if conf == nil {
+    key := "app.components." + id
+    conf, ok := app.config.Get(key).(map[string]interface{})
+    if !ok {
         panic("component not found: " + id)
     }
 
-    app.components[id] = CreateObject(conf)
+    // load dependencies first
+    for _, dep := range app.config.GetSliceString(key + "." + componentDependsKey) {
+        app.Get(dep)
+    }
+
+    config := make(map[string]interface{}, len(conf))
+    for k, v := range conf {
+        if k != componentDependsKey {
+            config[k] = v
+        }
+    }
+
+    app.configure(key, func() { component = CreateObject(config) })
+
+    if starter, ok := component.(IStarter); ok {
+        if e := starter.Start(); e != nil {
+            panic(fmt.Sprintf("failed to start component %s, %s", id, e))
+        }
+    }
+
+    app.lock.Lock()
+    app.components[id] = component
+    app.order = append(app.order, id)
+    app.lock.Unlock()
+
+    // reload component on config change
+    if r, ok := component.(IReloadable); ok {
+        app.config.Watch(key, func(key string, val interface{}) {
+            config, _ := val.(map[string]interface{})
+            r.Reload(config)
+        })
+    }
+
+    return component
+}
+
+// configure call fn to configure object of key, *ConfigError
+// of object is annotated with full key and config file.
+func (app *Application) configure(key string, fn func()) {
+    defer func() {
+        if v := recover(); v != nil {
+            if e, ok := v.(*ConfigError); ok && len(e.File) == 0 {
+                e.Key = key + "." + e.Key
+                e.File = app.config.source(e.Key)
+            }
+
+            panic(v)
+        }
+    }()
+
+    fn()
 }
 
 func (app *Application) coreComponents() map[string]string {
@@ -289,6 +439,8 @@ func (app *Application) coreComponents() map[string]string {
         "gzip":   "@pgo/Gzip",
         "file":   "@pgo/File",
 
+        "errorHandler": "@pgo/ErrorHandler",
+
         "http": "@pgo/Client/Http/Client",
     }
 }
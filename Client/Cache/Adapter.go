@@ -0,0 +1,259 @@
+package Cache
+
+import (
+    "time"
+
+    "github.com/pinguo/pgo"
+    "github.com/pinguo/pgo/Util"
+)
+
+// Adapter of Cache Client, add context support.
+// usage: cache := this.GetObject(Cache.AdapterClass).(*Cache.Adapter)
+type Adapter struct {
+    pgo.Object
+    client       *Client
+    panicRecover bool
+}
+
+func (a *Adapter) Construct(componentId ...string) {
+    id := defaultComponentId
+    if len(componentId) > 0 {
+        id = componentId[0]
+    }
+
+    a.client = pgo.App.Get(id).(*Client)
+    a.panicRecover = true
+}
+
+func (a *Adapter) SetPanicRecover(v bool) {
+    a.panicRecover = v
+}
+
+func (a *Adapter) GetClient() *Client {
+    return a.client
+}
+
+func (a *Adapter) handlePanic() {
+    if a.panicRecover {
+        if v := recover(); v != nil {
+            a.GetContext().Error(Util.ToString(v))
+        }
+    }
+}
+
+func (a *Adapter) GetOrSet(key string, expire time.Duration, loader func() (interface{}, error)) (*pgo.Value, error) {
+    profile := "Cache.GetOrSet"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+
+    return a.client.GetOrSet(key, expire, loader)
+}
+
+func (a *Adapter) GetOrSetWithTags(key string, expire time.Duration, loader func() (interface{}, error), tags ...string) (*pgo.Value, error) {
+    profile := "Cache.GetOrSetWithTags"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+
+    return a.client.GetOrSetWithTags(key, expire, loader, tags...)
+}
+
+func (a *Adapter) SetWithTags(key string, value interface{}, expire time.Duration, tags ...string) bool {
+    profile := "Cache.SetWithTags"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.SetWithTags(key, value, expire, tags...)
+}
+
+func (a *Adapter) InvalidateTags(tags ...string) bool {
+    profile := "Cache.InvalidateTags"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.InvalidateTags(tags...)
+}
+
+func (a *Adapter) GetInto(key string, ptr interface{}) bool {
+    profile := "Cache.GetInto"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    hit := a.client.GetInto(key, ptr)
+    a.GetContext().Counting(profile, Util.ToInt(hit), 1)
+    return hit
+}
+
+func (a *Adapter) GetOrSetInto(key string, ptr interface{}, expire time.Duration, loader func() (interface{}, error)) (bool, error) {
+    profile := "Cache.GetOrSetInto"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+
+    return a.client.GetOrSetInto(key, ptr, expire, loader)
+}
+
+func (a *Adapter) Get(key string) *pgo.Value {
+    profile := "Cache.Get"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    res, hit := a.client.Get(key), 0
+    if res != nil && res.Valid() {
+        hit = 1
+    }
+
+    a.GetContext().Counting(profile, hit, 1)
+    return res
+}
+
+func (a *Adapter) MGet(keys []string) map[string]*pgo.Value {
+    profile := "Cache.MGet"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    res, hit := a.client.MGet(keys), 0
+    for _, v := range res {
+        if v != nil && v.Valid() {
+            hit += 1
+        }
+    }
+
+    a.GetContext().Counting(profile, hit, len(keys))
+    return res
+}
+
+func (a *Adapter) Set(key string, value interface{}, expire ...time.Duration) bool {
+    profile := "Cache.Set"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.Set(key, value, expire...)
+}
+
+func (a *Adapter) MSet(items map[string]interface{}, expire ...time.Duration) bool {
+    profile := "Cache.MSet"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.MSet(items, expire...)
+}
+
+func (a *Adapter) Add(key string, value interface{}, expire ...time.Duration) bool {
+    profile := "Cache.Add"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.Add(key, value, expire...)
+}
+
+func (a *Adapter) MAdd(items map[string]interface{}, expire ...time.Duration) bool {
+    profile := "Cache.MAdd"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.MAdd(items, expire...)
+}
+
+func (a *Adapter) Del(key string) bool {
+    profile := "Cache.Del"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.Del(key)
+}
+
+func (a *Adapter) MDel(keys []string) bool {
+    profile := "Cache.MDel"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.MDel(keys)
+}
+
+func (a *Adapter) Exists(key string) bool {
+    profile := "Cache.Exists"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.Exists(key)
+}
+
+func (a *Adapter) Incr(key string, delta int) int {
+    profile := "Cache.Incr"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.Incr(key, delta)
+}
+
+func (a *Adapter) IncrWithExpire(key string, delta int, expire time.Duration) int {
+    profile := "Cache.IncrWithExpire"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.IncrWithExpire(key, delta, expire)
+}
+
+func (a *Adapter) Expire(key string, expire time.Duration) bool {
+    profile := "Cache.Expire"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.Expire(key, expire)
+}
+
+func (a *Adapter) TTL(key string) (time.Duration, bool) {
+    profile := "Cache.TTL"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.TTL(key)
+}
+
+func (a *Adapter) Touch(key string, expire time.Duration) *pgo.Value {
+    profile := "Cache.Touch"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    res, hit := a.client.Touch(key, expire), 0
+    if res != nil && res.Valid() {
+        hit = 1
+    }
+
+    a.GetContext().Counting(profile, hit, 1)
+    return res
+}
+
+func (a *Adapter) GetSet(key string, value interface{}, expire ...time.Duration) *pgo.Value {
+    profile := "Cache.GetSet"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.GetSet(key, value, expire...)
+}
+
+func (a *Adapter) CompareAndSwap(key string, old, new interface{}, expire ...time.Duration) bool {
+    profile := "Cache.CompareAndSwap"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.CompareAndSwap(key, old, new, expire...)
+}
@@ -0,0 +1,594 @@
+package Cache
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "math/rand"
+    "sync"
+    "time"
+
+    "github.com/pinguo/pgo"
+)
+
+// Cache Client component, backend is component id of any ICache,
+// if local is set, the local component(usually memory) is used as
+// L1 cache in front of backend, items of L1 expire in localExpire
+// or the expire of item, whichever is less, so L1 of different
+// instances may be stale in localExpire. nil result of loader is
+// cached in negativeExpire if it's not zero, expire of backend
+// is added a random jitter(ratio of expire) to avoid stampede.
+// entries can be set with tags and invalidated by tags, version
+// of tag is stored in backend and expires in tagExpire, entry is
+// invalid if version of any tag changed since the entry was set.
+// configuration:
+// cache:
+//     class: "@pgo/Client/Cache/Client"
+//     backend: "redis"
+//     local: "memory"
+//     expire: "10m"
+//     localExpire: "10s"
+//     negativeExpire: "0s"
+//     jitter: 0.1
+//     tagExpire: "720h"
+type Client struct {
+    backendId string
+    localId   string
+    backend   pgo.ICache
+    local     pgo.ICache
+
+    expire         time.Duration
+    localExpire    time.Duration
+    negativeExpire time.Duration
+    tagExpire      time.Duration
+    jitter         float64
+
+    flight group
+}
+
+func (c *Client) Construct() {
+    c.backendId = defaultBackend
+    c.expire = defaultExpire
+    c.localExpire = defaultLocalExpire
+    c.tagExpire = defaultTagExpire
+    c.jitter = defaultJitter
+    c.flight.calls = make(map[string]*call)
+}
+
+func (c *Client) Init() {
+    c.backend = c.getCache(c.backendId)
+    if len(c.localId) > 0 {
+        c.local = c.getCache(c.localId)
+    }
+}
+
+// SetBackend set component id of backend, default "redis"
+func (c *Client) SetBackend(id string) {
+    c.backendId = id
+}
+
+// SetLocal set component id of L1 cache, default "" means no L1 cache
+func (c *Client) SetLocal(id string) {
+    c.localId = id
+}
+
+// SetExpire set default expire, default "10m"
+func (c *Client) SetExpire(v string) {
+    c.expire = c.parseDuration("expire", v)
+}
+
+// SetLocalExpire set max expire of L1 cache, default "10s"
+func (c *Client) SetLocalExpire(v string) {
+    c.localExpire = c.parseDuration("localExpire", v)
+}
+
+// SetNegativeExpire set expire of nil result of loader, default "0s" means not cached
+func (c *Client) SetNegativeExpire(v string) {
+    c.negativeExpire = c.parseDuration("negativeExpire", v)
+}
+
+// SetTagExpire set expire of tag version, default "720h"
+func (c *Client) SetTagExpire(v string) {
+    c.tagExpire = c.parseDuration("tagExpire", v)
+}
+
+// SetJitter set ratio of random jitter added to expire, default 0.1
+func (c *Client) SetJitter(jitter float64) {
+    if jitter < 0 || jitter > 1 {
+        panic(fmt.Sprintf(errSetProp, "jitter", "jitter must be in [0, 1]"))
+    }
+
+    c.jitter = jitter
+}
+
+// GetBackend get backend cache
+func (c *Client) GetBackend() pgo.ICache {
+    return c.backend
+}
+
+// GetLocal get L1 cache, nil if not set
+func (c *Client) GetLocal() pgo.ICache {
+    return c.local
+}
+
+// GetOrSet get value of key, if key is missing, loader is called and
+// the result is cached in expire(0 for default expire), concurrent
+// calls of the same key share one loader call. error or panic of
+// loader is returned and not cached, nil result of loader is returned
+// as invalid value and cached if negativeExpire is set.
+func (c *Client) GetOrSet(key string, expire time.Duration, loader func() (interface{}, error)) (*pgo.Value, error) {
+    return c.GetOrSetWithTags(key, expire, loader)
+}
+
+// GetOrSetWithTags get value of key like GetOrSet, result of loader
+// is cached with tags, versions of tags are got before loader is
+// called, so the result is invalid if tags invalidated while loading.
+func (c *Client) GetOrSetWithTags(key string, expire time.Duration, loader func() (interface{}, error), tags ...string) (*pgo.Value, error) {
+    if v := c.unwrap(c.load(key)); v.Valid() {
+        return c.stripNegative(v), nil
+    }
+
+    return c.flight.do(key, func() (*pgo.Value, error) {
+        // value may be set by the call just finished
+        if v := c.unwrap(c.load(key)); v.Valid() {
+            return c.stripNegative(v), nil
+        }
+
+        versions := c.getTagVersions(tags)
+        val, e := callLoader(loader)
+        if e != nil {
+            return nil, e
+        }
+
+        if val == nil {
+            if c.negativeExpire > 0 {
+                c.set(key, c.wrap([]byte(negativeValue), versions), c.negativeExpire)
+            }
+
+            return pgo.NewValue(nil), nil
+        }
+
+        data := pgo.Encode(val)
+        c.set(key, c.wrap(data, versions), expire)
+        return pgo.NewValue(data), nil
+    })
+}
+
+// SetWithTags set value of key with tags in expire(0 for default expire)
+func (c *Client) SetWithTags(key string, value interface{}, expire time.Duration, tags ...string) bool {
+    data := c.wrap(pgo.Encode(value), c.getTagVersions(tags))
+    return c.set(key, data, expire)
+}
+
+// InvalidateTags invalidate all entries set with any of tags
+func (c *Client) InvalidateTags(tags ...string) bool {
+    if len(tags) == 0 {
+        return true
+    }
+
+    items, keys := make(map[string]interface{}, len(tags)), make([]string, 0, len(tags))
+    for _, tag := range tags {
+        items[tagKeyPrefix+tag] = newTagVersion()
+        keys = append(keys, tagKeyPrefix+tag)
+    }
+
+    if c.local != nil {
+        c.local.MDel(keys)
+    }
+
+    return c.backend.MSet(items, c.tagExpire)
+}
+
+// GetInto get value of key and decode to ptr, false if key is missing
+func (c *Client) GetInto(key string, ptr interface{}) bool {
+    v := c.Get(key)
+    if !v.Valid() {
+        return false
+    }
+
+    v.Decode(ptr)
+    return true
+}
+
+// GetOrSetInto get value of key by GetOrSet and decode to ptr,
+// false if loader returns nil result or error.
+func (c *Client) GetOrSetInto(key string, ptr interface{}, expire time.Duration, loader func() (interface{}, error)) (bool, error) {
+    v, e := c.GetOrSet(key, expire, loader)
+    if e != nil || !v.Valid() {
+        return false, e
+    }
+
+    if e := v.TryDecode(ptr); e != nil {
+        return false, e
+    }
+
+    return true, nil
+}
+
+func (c *Client) Get(key string) *pgo.Value {
+    return c.valueOf(c.load(key))
+}
+
+func (c *Client) MGet(keys []string) map[string]*pgo.Value {
+    result, missing := make(map[string]*pgo.Value, len(keys)), keys
+    if c.local != nil {
+        missing = make([]string, 0)
+        for key, v := range c.local.MGet(keys) {
+            if v.Valid() {
+                result[key] = c.valueOf(v)
+            } else {
+                missing = append(missing, key)
+            }
+        }
+    }
+
+    if len(missing) > 0 {
+        for key, v := range c.backend.MGet(missing) {
+            if v.Valid() && c.local != nil {
+                c.local.Set(key, v.Bytes(), c.localExpire)
+            }
+
+            result[key] = c.valueOf(v)
+        }
+    }
+
+    return result
+}
+
+func (c *Client) Set(key string, value interface{}, expire ...time.Duration) bool {
+    return c.set(key, pgo.Encode(value), c.getExpire(expire...))
+}
+
+func (c *Client) MSet(items map[string]interface{}, expire ...time.Duration) bool {
+    data := make(map[string]interface{}, len(items))
+    for key, value := range items {
+        data[key] = pgo.Encode(value)
+    }
+
+    ttl := c.getExpire(expire...)
+    if c.local != nil {
+        c.local.MSet(data, c.getLocalExpire(ttl))
+    }
+
+    return c.backend.MSet(data, c.addJitter(ttl))
+}
+
+func (c *Client) Add(key string, value interface{}, expire ...time.Duration) bool {
+    data, ttl := pgo.Encode(value), c.getExpire(expire...)
+    if !c.backend.Add(key, data, c.addJitter(ttl)) {
+        return false
+    }
+
+    if c.local != nil {
+        c.local.Set(key, data, c.getLocalExpire(ttl))
+    }
+
+    return true
+}
+
+func (c *Client) MAdd(items map[string]interface{}, expire ...time.Duration) bool {
+    data, keys := make(map[string]interface{}, len(items)), make([]string, 0, len(items))
+    for key, value := range items {
+        data[key] = pgo.Encode(value)
+        keys = append(keys, key)
+    }
+
+    // items may be added partially, so drop them from L1
+    if c.local != nil {
+        defer c.local.MDel(keys)
+    }
+
+    return c.backend.MAdd(data, c.addJitter(c.getExpire(expire...)))
+}
+
+func (c *Client) Del(key string) bool {
+    c.delLocal(key)
+    return c.backend.Del(key)
+}
+
+func (c *Client) MDel(keys []string) bool {
+    if c.local != nil {
+        c.local.MDel(keys)
+    }
+
+    return c.backend.MDel(keys)
+}
+
+func (c *Client) Exists(key string) bool {
+    return c.Get(key).Valid()
+}
+
+func (c *Client) Incr(key string, delta int) int {
+    c.delLocal(key)
+    return c.backend.Incr(key, delta)
+}
+
+func (c *Client) IncrWithExpire(key string, delta int, expire time.Duration) int {
+    c.delLocal(key)
+    return c.backend.IncrWithExpire(key, delta, expire)
+}
+
+// Expire set expire of key in backend, L1 is invalidated
+// since expire of L1 item may be longer than the new one.
+func (c *Client) Expire(key string, expire time.Duration) bool {
+    c.delLocal(key)
+    return c.backend.Expire(key, expire)
+}
+
+// TTL get remaining ttl of key in backend, including jitter
+func (c *Client) TTL(key string) (time.Duration, bool) {
+    return c.backend.TTL(key)
+}
+
+func (c *Client) Touch(key string, expire time.Duration) *pgo.Value {
+    c.delLocal(key)
+    return c.valueOf(c.backend.Touch(key, expire))
+}
+
+// GetSet set value of key and return the old value, 0 means never expire
+func (c *Client) GetSet(key string, value interface{}, expire ...time.Duration) *pgo.Value {
+    c.delLocal(key)
+    return c.valueOf(c.backend.GetSet(key, pgo.Encode(value), c.getSwapExpire(expire...)))
+}
+
+// CompareAndSwap set value of key if its value equals to old, entries
+// set with tags never match since they are wrapped, 0 means never expire.
+func (c *Client) CompareAndSwap(key string, old, new interface{}, expire ...time.Duration) bool {
+    c.delLocal(key)
+    return c.backend.CompareAndSwap(key, pgo.Encode(old), pgo.Encode(new), c.getSwapExpire(expire...))
+}
+
+// delLocal delete key from L1 if L1 is set
+func (c *Client) delLocal(key string) {
+    if c.local != nil {
+        c.local.Del(key)
+    }
+}
+
+// load get raw value of key from L1 or backend, value
+// got from backend is set to L1, negative value included.
+func (c *Client) load(key string) *pgo.Value {
+    if c.local != nil {
+        if v := c.local.Get(key); v.Valid() {
+            return v
+        }
+    }
+
+    v := c.backend.Get(key)
+    if v.Valid() && c.local != nil {
+        c.local.Set(key, v.Bytes(), c.localExpire)
+    }
+
+    return v
+}
+
+// set encoded data to backend and L1
+func (c *Client) set(key string, data []byte, expire time.Duration) bool {
+    if expire <= 0 {
+        expire = c.expire
+    }
+
+    if c.local != nil {
+        c.local.Set(key, data, c.getLocalExpire(expire))
+    }
+
+    return c.backend.Set(key, data, c.addJitter(expire))
+}
+
+// valueOf get value for caller from raw value
+func (c *Client) valueOf(v *pgo.Value) *pgo.Value {
+    return c.stripNegative(c.unwrap(v))
+}
+
+// stripNegative convert negative value to invalid value
+func (c *Client) stripNegative(v *pgo.Value) *pgo.Value {
+    if data, ok := rawBytes(v); ok && string(data) == negativeValue {
+        return pgo.NewValue(nil)
+    }
+
+    return v
+}
+
+// wrap data with versions of tags, data is not wrapped if no tags
+func (c *Client) wrap(data []byte, versions map[string]string) []byte {
+    if len(versions) == 0 {
+        return data
+    }
+
+    output, e := json.Marshal(&taggedValue{Tags: versions, Data: data})
+    if e != nil {
+        panic(fmt.Sprintf("cache: failed to encode tagged value, %s", e))
+    }
+
+    return append([]byte(taggedPrefix), output...)
+}
+
+// unwrap convert tagged value with changed versions to
+// invalid value, data of valid tagged value is unwrapped,
+// negative value is kept for caller to check the hit.
+func (c *Client) unwrap(v *pgo.Value) *pgo.Value {
+    data, ok := rawBytes(v)
+    if !ok || !bytes.HasPrefix(data, []byte(taggedPrefix)) {
+        return v
+    }
+
+    tv := &taggedValue{}
+    if e := json.Unmarshal(data[len(taggedPrefix):], tv); e != nil {
+        return pgo.NewValue(nil)
+    }
+
+    keys := make([]string, 0, len(tv.Tags))
+    for tag := range tv.Tags {
+        keys = append(keys, tagKeyPrefix+tag)
+    }
+
+    // tag missing from result is stale as well
+    versions := c.MGet(keys)
+    for tag, version := range tv.Tags {
+        if v := versions[tagKeyPrefix+tag]; v == nil || !v.Valid() || v.String() != version {
+            return pgo.NewValue(nil)
+        }
+    }
+
+    return pgo.NewValue(tv.Data)
+}
+
+// getTagVersions get current versions of tags, version
+// is created if tag has no version or version expired.
+func (c *Client) getTagVersions(tags []string) map[string]string {
+    if len(tags) == 0 {
+        return nil
+    }
+
+    keys := make([]string, 0, len(tags))
+    for _, tag := range tags {
+        keys = append(keys, tagKeyPrefix+tag)
+    }
+
+    versions := c.MGet(keys)
+    result, missing := make(map[string]string, len(tags)), make(map[string]interface{})
+    for i, tag := range tags {
+        if v := versions[keys[i]]; v != nil && v.Valid() {
+            result[tag] = v.String()
+        } else if _, ok := result[tag]; !ok {
+            result[tag] = newTagVersion()
+            missing[keys[i]] = result[tag]
+        }
+    }
+
+    if len(missing) > 0 {
+        c.backend.MSet(missing, c.tagExpire)
+    }
+
+    return result
+}
+
+func (c *Client) getExpire(expire ...time.Duration) time.Duration {
+    if len(expire) > 0 && expire[0] > 0 {
+        return expire[0]
+    }
+
+    return c.expire
+}
+
+// getSwapExpire get expire of GetSet and CompareAndSwap,
+// explicit 0 means never expire and jitter is not added.
+func (c *Client) getSwapExpire(expire ...time.Duration) time.Duration {
+    if len(expire) > 0 && expire[0] == 0 {
+        return 0
+    }
+
+    return c.addJitter(c.getExpire(expire...))
+}
+
+func (c *Client) getLocalExpire(expire time.Duration) time.Duration {
+    if expire < c.localExpire {
+        return expire
+    }
+
+    return c.localExpire
+}
+
+func (c *Client) addJitter(expire time.Duration) time.Duration {
+    if c.jitter > 0 {
+        expire += time.Duration(rand.Float64() * c.jitter * float64(expire))
+    }
+
+    return expire
+}
+
+func (c *Client) getCache(id string) pgo.ICache {
+    cache, ok := pgo.App.Get(id).(pgo.ICache)
+    if !ok {
+        panic(fmt.Sprintf("cache: component %s is not a cache", id))
+    }
+
+    return cache
+}
+
+func (c *Client) parseDuration(name, v string) time.Duration {
+    d, e := time.ParseDuration(v)
+    if e != nil {
+        panic(fmt.Sprintf(errSetProp, name, e.Error()))
+    }
+
+    return d
+}
+
+// call loader, panic of loader is returned as error
+func callLoader(loader func() (interface{}, error)) (val interface{}, err error) {
+    defer func() {
+        if v := recover(); v != nil {
+            val, err = nil, pgo.ToError(v)
+        }
+    }()
+
+    return loader()
+}
+
+type call struct {
+    wg  sync.WaitGroup
+    val *pgo.Value
+    err error
+}
+
+// group suppress duplicate calls of the same key
+type group struct {
+    lock  sync.Mutex
+    calls map[string]*call
+}
+
+// do call fn once for concurrent calls of the same key,
+// panic of fn is returned as error to all callers.
+func (g *group) do(key string, fn func() (*pgo.Value, error)) (*pgo.Value, error) {
+    g.lock.Lock()
+    if cl, ok := g.calls[key]; ok {
+        g.lock.Unlock()
+        cl.wg.Wait()
+        return cl.val, cl.err
+    }
+
+    cl := &call{}
+    cl.wg.Add(1)
+    g.calls[key] = cl
+    g.lock.Unlock()
+
+    func() {
+        defer func() {
+            if v := recover(); v != nil {
+                cl.val, cl.err = nil, pgo.ToError(v)
+            }
+        }()
+
+        cl.val, cl.err = fn()
+    }()
+
+    g.lock.Lock()
+    delete(g.calls, key)
+    g.lock.Unlock()
+    cl.wg.Done()
+
+    return cl.val, cl.err
+}
+
+// taggedValue data of entry with versions of tags
+type taggedValue struct {
+    Tags map[string]string `json:"t"`
+    Data []byte            `json:"d"`
+}
+
+// rawBytes get underlying bytes of value stored by cache
+func rawBytes(v *pgo.Value) ([]byte, bool) {
+    switch d := v.Data().(type) {
+    case []byte:
+        return d, true
+    case string:
+        return []byte(d), true
+    }
+
+    return nil, false
+}
+
+func newTagVersion() string {
+    return fmt.Sprintf("%x.%x", time.Now().UnixNano(), rand.Int31())
+}
@@ -0,0 +1,36 @@
+package Cache
+
+import (
+    "time"
+
+    "github.com/pinguo/pgo"
+)
+
+const (
+    AdapterClass = "@pgo/Client/Cache/Adapter"
+
+    defaultComponentId = "cache"
+    defaultBackend     = "redis"
+    defaultExpire      = 10 * time.Minute
+    defaultLocalExpire = 10 * time.Second
+    defaultJitter      = 0.1
+    defaultTagExpire   = 720 * time.Hour
+
+    // value stored for negative result of loader
+    negativeValue = "\x00pgo:cache:nil\x00"
+
+    // prefix of value stored with versions of tags
+    taggedPrefix = "\x00pgo:cache:tags\x00"
+
+    // key prefix of tag version
+    tagKeyPrefix = "pgo:cache:tag:"
+
+    errSetProp = "cache: failed to set %s, %s"
+)
+
+func init() {
+    container := pgo.App.GetContainer()
+
+    container.Bind(&Adapter{})
+    container.Bind(&Client{})
+}
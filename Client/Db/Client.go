@@ -76,6 +76,41 @@ func (c *Client) Init() {
     }
 }
 
+// Start verify master and slave db instances are reachable,
+// db instances are opened by Init without connecting.
+func (c *Client) Start() error {
+    return c.HealthCheck()
+}
+
+// Stop close master and slave db instances
+func (c *Client) Stop() error {
+    var err error
+    for _, db := range append([]*sql.DB{c.masterDb}, c.slaveDbs...) {
+        if db != nil {
+            if e := db.Close(); e != nil {
+                err = e
+            }
+        }
+    }
+
+    return err
+}
+
+// HealthCheck ping master and slave db instances
+func (c *Client) HealthCheck() error {
+    if c.masterDb == nil {
+        return fmt.Errorf("Db: client is not initialized")
+    }
+
+    for _, db := range append([]*sql.DB{c.masterDb}, c.slaveDbs...) {
+        if e := db.Ping(); e != nil {
+            return e
+        }
+    }
+
+    return nil
+}
+
 // SetDriver set driver db use, eg. "mysql"
 func (c *Client) SetDriver(driver string) {
     c.driver = driver
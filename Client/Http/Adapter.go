@@ -43,6 +43,13 @@ func (a *Adapter) handlePanic() {
     }
 }
 
+// recoverError recover panic as error, used by error-returning methods
+func (a *Adapter) recoverError(err *error) {
+    if v := recover(); v != nil {
+        *err = pgo.ToError(v)
+    }
+}
+
 // Get perform a get request
 func (a *Adapter) Get(addr string, data interface{}, option ...*Option) *http.Response {
     profile := baseUrl(addr)
@@ -73,6 +80,36 @@ func (a *Adapter) Do(req *http.Request, option ...*Option) *http.Response {
     return a.client.Do(req, option...)
 }
 
+// GetE perform a get request, error is returned instead of panic
+func (a *Adapter) GetE(addr string, data interface{}, option ...*Option) (res *http.Response, err error) {
+    profile := baseUrl(addr)
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.recoverError(&err)
+
+    return a.client.GetE(addr, data, option...)
+}
+
+// PostE perform a post request, error is returned instead of panic
+func (a *Adapter) PostE(addr string, data interface{}, option ...*Option) (res *http.Response, err error) {
+    profile := baseUrl(addr)
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.recoverError(&err)
+
+    return a.client.PostE(addr, data, option...)
+}
+
+// DoE perform a single request, error is returned instead of panic
+func (a *Adapter) DoE(req *http.Request, option ...*Option) (res *http.Response, err error) {
+    profile := baseUrl(req.URL.String())
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.recoverError(&err)
+
+    return a.client.DoE(req, option...)
+}
+
 // DoMulti perform multi requests concurrently
 func (a *Adapter) DoMulti(requests []*http.Request, option ...*Option) []*http.Response {
     if num := len(option); num != 0 && num != len(requests) {
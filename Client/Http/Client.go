@@ -72,6 +72,16 @@ func (c *Client) SetTimeout(v string) {
 // url.Values or map with string key. option is an optional
 // configuration object to specify header, cookie etc.
 func (c *Client) Get(addr string, data interface{}, option ...*Option) *http.Response {
+    res, e := c.GetE(addr, data, option...)
+    if e != nil {
+        panic(e)
+    }
+
+    return res
+}
+
+// GetE perform a get request like Get, error is returned instead of panic.
+func (c *Client) GetE(addr string, data interface{}, option ...*Option) (*http.Response, error) {
     var query url.Values
     switch v := data.(type) {
     case nil:
@@ -90,7 +100,7 @@ func (c *Client) Get(addr string, data interface{}, option ...*Option) *http.Res
         }
 
     default:
-        panic(fmt.Sprintf("http get invalid data type: %T", data))
+        return nil, fmt.Errorf("http get invalid data type: %T", data)
     }
 
     if len(query) != 0 {
@@ -103,10 +113,10 @@ func (c *Client) Get(addr string, data interface{}, option ...*Option) *http.Res
 
     req, err := http.NewRequest("GET", addr, nil)
     if err != nil {
-        panic("http get bad request, " + err.Error())
+        return nil, fmt.Errorf("http get bad request, %w", err)
     }
 
-    return c.Do(req, option...)
+    return c.DoE(req, option...)
 }
 
 // Post perform a post request, and return a response pointer.
@@ -116,6 +126,16 @@ func (c *Client) Get(addr string, data interface{}, option ...*Option) *http.Res
 // Content-Type header will be set to "application/x-www-form-urlencoded".
 // option is an optional configuration object to specify header, cookie etc.
 func (c *Client) Post(addr string, data interface{}, option ...*Option) *http.Response {
+    res, e := c.PostE(addr, data, option...)
+    if e != nil {
+        panic(e)
+    }
+
+    return res
+}
+
+// PostE perform a post request like Post, error is returned instead of panic.
+func (c *Client) PostE(addr string, data interface{}, option ...*Option) (*http.Response, error) {
     var body io.Reader
     var contentType string
 
@@ -148,23 +168,34 @@ func (c *Client) Post(addr string, data interface{}, option ...*Option) *http.Re
         body = v
 
     default:
-        panic(fmt.Sprintf("http post invalid data type: %T", data))
+        return nil, fmt.Errorf("http post invalid data type: %T", data)
     }
 
     req, err := http.NewRequest("POST", addr, body)
     if err != nil {
-        panic("http post bad request, " + err.Error())
+        return nil, fmt.Errorf("http post bad request, %w", err)
     }
 
     if contentType != "" {
         req.Header.Set("Content-Type", contentType)
     }
 
-    return c.Do(req, option...)
+    return c.DoE(req, option...)
 }
 
 // Do perform a request specified by req param, and return response pointer.
 func (c *Client) Do(req *http.Request, option ...*Option) *http.Response {
+    res, e := c.DoE(req, option...)
+    if e != nil {
+        panic(e)
+    }
+
+    return res
+}
+
+// DoE perform a request like Do, error is returned instead of panic,
+// kind of error is pgo.ErrTimeout or pgo.ErrNetwork.
+func (c *Client) DoE(req *http.Request, option ...*Option) (*http.Response, error) {
     if c.userAgent != "" {
         req.Header.Set("User-Agent", c.userAgent)
     }
@@ -190,8 +221,8 @@ func (c *Client) Do(req *http.Request, option ...*Option) *http.Response {
     ctx, _ := context.WithTimeout(req.Context(), timeout)
     res, err := c.client.Do(req.WithContext(ctx))
     if err != nil {
-        panic("http request failed, " + err.Error())
+        return nil, pgo.NewNetError(err, "http request failed, %s", err.Error())
     }
 
-    return res
+    return res, nil
 }
@@ -145,6 +145,71 @@ func (a *Adapter) Incr(key string, delta int) int {
     return a.client.Incr(key, delta)
 }
 
+func (a *Adapter) IncrWithExpire(key string, delta int, expire time.Duration) int {
+    profile := "Memcache.IncrWithExpire"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.IncrWithExpire(key, delta, expire)
+}
+
+func (a *Adapter) Expire(key string, expire time.Duration) bool {
+    profile := "Memcache.Expire"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.Expire(key, expire)
+}
+
+func (a *Adapter) TTL(key string) (time.Duration, bool) {
+    profile := "Memcache.TTL"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.TTL(key)
+}
+
+func (a *Adapter) Touch(key string, expire time.Duration) *pgo.Value {
+    profile := "Memcache.Touch"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    res, hit := a.client.Touch(key, expire), 0
+    if res != nil && res.Valid() {
+        hit = 1
+    }
+
+    a.GetContext().Counting(profile, hit, 1)
+    return res
+}
+
+func (a *Adapter) GetSet(key string, value interface{}, expire ...time.Duration) *pgo.Value {
+    profile := "Memcache.GetSet"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.GetSet(key, value, expire...)
+}
+
+func (a *Adapter) CompareAndSwap(key string, old, new interface{}, expire ...time.Duration) bool {
+    profile := "Memcache.CompareAndSwap"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.CompareAndSwap(key, old, new, expire...)
+}
+
+// NewLock create distributed lock of key, see pgo.Lock
+func (a *Adapter) NewLock(key string, ttl time.Duration) *pgo.Lock {
+    return a.client.NewLock(key, ttl)
+}
+
 func (a *Adapter) Retrieve(cmd, key string) *Item {
     profile := "Memcache.Retrieve"
     a.GetContext().ProfileStart(profile)
@@ -1,6 +1,7 @@
 package Memcache
 
 import (
+    "bytes"
     "sync"
     "sync/atomic"
     "time"
@@ -98,7 +99,7 @@ func (c *Client) MDel(keys []string) bool {
 }
 
 func (c *Client) Exists(key string) bool {
-    return c.Get(key) != nil
+    return c.Get(key).Valid()
 }
 
 func (c *Client) Incr(key string, delta int) int {
@@ -109,6 +110,96 @@ func (c *Client) Incr(key string, delta int) int {
     return conn.Increment(newKey, delta)
 }
 
+// IncrWithExpire increase value of key, key not exists
+// is added with expire, 0 means never expire, value is
+// not decreased below 0 by negative delta.
+func (c *Client) IncrWithExpire(key string, delta int, expire time.Duration) int {
+    newKey := c.BuildKey(key)
+    conn := c.GetConnByKey(newKey)
+    defer conn.Close(false)
+
+    return conn.IncrementWithExpire(newKey, delta, expireSeconds(expire))
+}
+
+// Expire set expire of key, 0 means never expire,
+// false is returned if key not exists.
+func (c *Client) Expire(key string, expire time.Duration) bool {
+    newKey := c.BuildKey(key)
+    conn := c.GetConnByKey(newKey)
+    defer conn.Close(false)
+
+    return conn.Touch(newKey, expireSeconds(expire))
+}
+
+// TTL get remaining ttl of key, 0 means never expire,
+// ok is false if key not exists, require memcached 1.6+.
+func (c *Client) TTL(key string) (time.Duration, bool) {
+    newKey := c.BuildKey(key)
+    conn := c.GetConnByKey(newKey)
+    defer conn.Close(false)
+
+    ttl, ok := conn.TTL(newKey)
+    if !ok || ttl < 0 {
+        return 0, ok
+    }
+
+    return time.Duration(ttl) * time.Second, true
+}
+
+// Touch get value of key and set expire of key,
+// 0 means never expire, require memcached 1.5.3+.
+func (c *Client) Touch(key string, expire time.Duration) *pgo.Value {
+    newKey := c.BuildKey(key)
+    conn := c.GetConnByKey(newKey)
+    defer conn.Close(false)
+
+    if items := conn.GetAndTouch(expireSeconds(expire), newKey); len(items) == 1 {
+        return pgo.NewValue(items[0].Data)
+    }
+    return pgo.NewValue(nil)
+}
+
+// GetSet set value of key and return the old value,
+// value is swapped by gets and cas, panic if conflicts
+// with other clients more than max retries, 0 means never expire.
+func (c *Client) GetSet(key string, value interface{}, expire ...time.Duration) *pgo.Value {
+    newKey := c.BuildKey(key)
+    conn := c.GetConnByKey(newKey)
+    defer conn.Close(false)
+
+    expire = append(expire, defaultExpire)
+    item := &Item{Key: newKey, Data: pgo.Encode(value)}
+    for retry := 0; retry < maxRetries; retry++ {
+        conn.ExtendDeadLine()
+        if items := conn.Retrieve(CmdGets, newKey); len(items) == 0 {
+            if conn.Store(CmdAdd, item, expireSeconds(expire[0])) {
+                return pgo.NewValue(nil)
+            }
+        } else if item.CasId = items[0].CasId; conn.Store(CmdCas, item, expireSeconds(expire[0])) {
+            return pgo.NewValue(items[0].Data)
+        }
+    }
+
+    panic(errCasFailed + key)
+}
+
+// CompareAndSwap set value of key if encoded value of key equals
+// to encoded old, false if key not exists, 0 means never expire.
+func (c *Client) CompareAndSwap(key string, old, new interface{}, expire ...time.Duration) bool {
+    newKey := c.BuildKey(key)
+    conn := c.GetConnByKey(newKey)
+    defer conn.Close(false)
+
+    items := conn.Retrieve(CmdGets, newKey)
+    if len(items) == 0 || !bytes.Equal(items[0].Data, pgo.Encode(old)) {
+        return false
+    }
+
+    expire = append(expire, defaultExpire)
+    item := &Item{Key: newKey, Data: pgo.Encode(new), CasId: items[0].CasId}
+    return conn.Store(CmdCas, item, expireSeconds(expire[0]))
+}
+
 func (c *Client) Retrieve(cmd, key string) *Item {
     newKey := c.BuildKey(key)
     conn := c.GetConnByKey(newKey)
@@ -149,7 +240,7 @@ func (c *Client) Store(cmd string, item *Item, expire ...time.Duration) bool {
     defer conn.Close(false)
 
     expire = append(expire, defaultExpire)
-    return conn.Store(cmd, item, int(expire[0]/time.Second))
+    return conn.Store(cmd, item, expireSeconds(expire[0]))
 }
 
 func (c *Client) MultiStore(cmd string, items []*Item, expire ...time.Duration) bool {
@@ -168,7 +259,7 @@ func (c *Client) MultiStore(cmd string, items []*Item, expire ...time.Duration)
         go c.RunAddrFunc(addr, nil, wg, func(conn *Conn, keys []string) {
             for _, item := range addrItems[addr] {
                 conn.ExtendDeadLine() // extend deadline for every store
-                if ok := conn.Store(cmd, item, int(expire[0]/time.Second)); ok {
+                if ok := conn.Store(cmd, item, expireSeconds(expire[0])); ok {
                     atomic.AddUint32(&success, 1)
                 }
             }
@@ -178,3 +269,16 @@ func (c *Client) MultiStore(cmd string, items []*Item, expire ...time.Duration)
     wg.Wait()
     return success == uint32(len(items))
 }
+
+// expireSeconds convert expire to expiration time of memcache, expire
+// longer than 30 days is converted to unix timestamp, expire shorter
+// than 1 second is rounded up to avoid being treated as never expire.
+func expireSeconds(expire time.Duration) int {
+    if expire > maxRelativeExpire {
+        return int(time.Now().Add(expire).Unix())
+    } else if expire > 0 && expire < time.Second {
+        return 1
+    }
+
+    return int(expire / time.Second)
+}
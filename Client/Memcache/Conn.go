@@ -125,6 +125,53 @@ func (c *Conn) Retrieve(cmd string, keys ...string) (items []*Item) {
         c.parseError(errSendFailed+e.Error(), true)
     }
 
+    return c.readItems(cmd == CmdGets)
+}
+
+// execute gat command, get items of keys and update
+// expiration time of them, require memcached 1.5.3+,
+// expire is expiration time, either unix timestamp or
+// offset in seconds from now, 0 means never expires.
+func (c *Conn) GetAndTouch(expire int, keys ...string) []*Item {
+    if len(keys) < 1 {
+        panic(errEmptyKeys)
+    }
+
+    fmt.Fprintf(c.rw, "%s %d", CmdGat, expire)
+    for _, v := range keys {
+        c.rw.WriteByte(' ')
+        c.rw.WriteString(v)
+    }
+
+    c.rw.Write(lineEnding)
+    if e := c.rw.Flush(); e != nil {
+        c.parseError(errSendFailed+e.Error(), true)
+    }
+
+    return c.readItems(false)
+}
+
+// execute meta get command to get remaining ttl of key in
+// seconds, -1 means never expires, require memcached 1.6+,
+// ok is false if key not exists.
+func (c *Conn) TTL(key string) (ttl int, ok bool) {
+    fmt.Fprintf(c.rw, "%s %s t\r\n", CmdMetaGet, key)
+    if e := c.rw.Flush(); e != nil {
+        c.parseError(errSendFailed+e.Error(), true)
+    }
+
+    if line, e := c.rw.ReadSlice('\n'); e != nil {
+        c.parseError(errReadFailed+e.Error(), true)
+    } else if bytes.Equal(line, replyMetaMiss) {
+        return 0, false
+    } else if _, e := fmt.Sscanf(string(line), "HD t%d\r\n", &ttl); e != nil {
+        c.parseError(errBase+string(line), false)
+    }
+    return ttl, true
+}
+
+// read items of retrieve command, cas id is read if withCas
+func (c *Conn) readItems(withCas bool) (items []*Item) {
     for {
         line, e := c.rw.ReadSlice('\n')
         if e != nil {
@@ -136,7 +183,7 @@ func (c *Conn) Retrieve(cmd string, keys ...string) (items []*Item) {
         }
 
         rd, item, size := bytes.NewReader(line), new(Item), 0
-        if cmd == CmdGet {
+        if !withCas {
             _, e = fmt.Fscanf(rd, "VALUE %s %d %d\r\n", &item.Key, &item.Flags, &size)
         } else {
             _, e = fmt.Fscanf(rd, "VALUE %s %d %d %d\r\n", &item.Key, &item.Flags, &size, &item.CasId)
@@ -182,6 +229,36 @@ func (c *Conn) Delete(key string) bool {
 // if the data is not uint64 representation, function panic.
 // if decrease data below 0, new data will be 0.
 func (c *Conn) Increment(key string, delta int) int {
+    return c.IncrementWithExpire(key, delta, 0)
+}
+
+// execute increment/decrement command like Increment,
+// if key not found, key is added with expire, expire is
+// expiration time, either unix timestamp or offset in seconds,
+// function panics if key keeps being added and deleted by others.
+func (c *Conn) IncrementWithExpire(key string, delta int, expire int) int {
+    for retry := 0; retry < maxRetries; retry++ {
+        if result, ok := c.increment(key, delta); ok {
+            return result
+        }
+
+        initial := delta
+        if initial < 0 {
+            initial = 0
+        }
+
+        // add fails if key is created by others, incr again
+        data := strconv.AppendInt(nil, int64(initial), 10)
+        if c.Store(CmdAdd, &Item{Key: key, Data: data}, expire) {
+            return initial
+        }
+    }
+
+    panic(errCasFailed + key)
+}
+
+// execute increment/decrement command, ok is false if key not found
+func (c *Conn) increment(key string, delta int) (int, bool) {
     if delta > 0 {
         fmt.Fprintf(c.rw, "%s %s %d\r\n", CmdIncr, key, delta)
     } else {
@@ -194,21 +271,15 @@ func (c *Conn) Increment(key string, delta int) int {
 
     if line, e := c.rw.ReadSlice('\n'); e != nil {
         c.parseError(errReadFailed+e.Error(), true)
-        return 0
+        return 0, false
     } else if bytes.Equal(line, replyNotFound) {
-        if delta < 0 {
-            delta = 0
-        }
-
-        data := strconv.AppendInt(nil, int64(delta), 10)
-        c.Store(CmdSet, &Item{Key: key, Data: data}, 0)
-        return delta
+        return 0, false
     } else {
         rd, result := bytes.NewReader(line), 0
         if _, e := fmt.Fscanf(rd, "%d\r\n", &result); e != nil {
             c.parseError(errBase+string(line), false)
         }
-        return result
+        return result, true
     }
 }
 
@@ -21,6 +21,12 @@ const (
     maxProbeInterval = 30 * time.Second
     minProbeInterval = 1 * time.Second
 
+    // max retries of optimistic updates by cas
+    maxRetries = 10
+
+    // expire longer than 30 days is sent as unix timestamp
+    maxRelativeExpire = 30 * 24 * time.Hour
+
     CmdCas      = "cas"
     CmdAdd      = "add"
     CmdSet      = "set"
@@ -33,6 +39,8 @@ const (
     CmdIncr     = "incr"
     CmdDecr     = "decr"
     CmdTouch    = "touch"
+    CmdGat      = "gat"
+    CmdMetaGet  = "mg"
     CmdStats    = "stats"
     CmdFlushAll = "flush_all"
     CmdVersion  = "version"
@@ -45,6 +53,7 @@ const (
     errReadFailed = "memcache: read response failed, "
     errEmptyKeys  = "memcache: empty keys"
     errCorrupted  = "memcache: corrupted response, "
+    errCasFailed  = "memcache: too many cas conflicts, "
 )
 
 var (
@@ -57,6 +66,7 @@ var (
     replyDeleted   = []byte("DELETED\r\n")
     replyTouched   = []byte("TOUCHED\r\n")
     replyEnd       = []byte("END\r\n")
+    replyMetaMiss  = []byte("EN\r\n")
 )
 
 func init() {
@@ -0,0 +1,48 @@
+package Memcache
+
+import (
+    "bytes"
+    "time"
+
+    "github.com/pinguo/pgo"
+)
+
+// NewLock create distributed lock of key, ttl is expire of lock,
+// lock is stored as key with token as value, see pgo.Lock. lock
+// on memcache is best-effort, it's lost if key is evicted, and
+// the precision of ttl is second.
+func (c *Client) NewLock(key string, ttl time.Duration) *pgo.Lock {
+    return pgo.NewLock(c, key, ttl)
+}
+
+// AcquireLock add key with token, implement pgo.ILockStore
+func (c *Client) AcquireLock(key, token string, ttl time.Duration) bool {
+    return c.Add(key, token, ttl)
+}
+
+// RenewLock reset expire of key by cas if value of
+// key is token, implement pgo.ILockStore
+func (c *Client) RenewLock(key, token string, ttl time.Duration) bool {
+    return c.casLock(key, token, expireSeconds(ttl))
+}
+
+// ReleaseLock expire key immediately by cas if value of
+// key is token, implement pgo.ILockStore
+func (c *Client) ReleaseLock(key, token string) bool {
+    return c.casLock(key, token, -1)
+}
+
+// casLock store token with expire if value of key is token
+func (c *Client) casLock(key, token string, expire int) bool {
+    newKey := c.BuildKey(key)
+    conn := c.GetConnByKey(newKey)
+    defer conn.Close(false)
+
+    data := pgo.Encode(token)
+    items := conn.Retrieve(CmdGets, newKey)
+    if len(items) == 0 || !bytes.Equal(items[0].Data, data) {
+        return false
+    }
+
+    return conn.Store(CmdCas, &Item{Key: newKey, Data: data, CasId: items[0].CasId}, expire)
+}
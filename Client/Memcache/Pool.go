@@ -7,6 +7,7 @@ import (
     "sync"
     "time"
 
+    "github.com/pinguo/pgo"
     "github.com/pinguo/pgo/Util"
 )
 
@@ -32,9 +33,13 @@ type Pool struct {
     maxIdleTime   time.Duration
     netTimeout    time.Duration
     probeInterval time.Duration
+
+    stop     chan struct{}
+    stopOnce sync.Once
 }
 
 func (p *Pool) Construct() {
+    p.stop = make(chan struct{})
     p.hashRing = Util.NewHashRing()
     p.connLists = make(map[string]*connList)
     p.servers = make(map[string]*serverInfo)
@@ -66,6 +71,24 @@ func (p *Pool) Init() {
     }
 }
 
+// Stop stop probe loop and close idle connections
+func (p *Pool) Stop() error {
+    p.stopOnce.Do(func() { close(p.stop) })
+
+    p.lock.Lock()
+    defer p.lock.Unlock()
+
+    for addr, list := range p.connLists {
+        for conn := list.head; conn != nil; conn = conn.next {
+            conn.nc.Close()
+        }
+
+        delete(p.connLists, addr)
+    }
+
+    return nil
+}
+
 func (p *Pool) SetPrefix(prefix string) {
     p.prefix = prefix
 }
@@ -269,11 +292,13 @@ func (p *Pool) probeServer(addr string) {
         p.servers[addr].disabled = true
         p.hashRing.DelNode(addr)
         p.lock.Unlock()
+        pgo.App.GetEventBus().Trigger(pgo.EventPoolDown, nil, "memcache", addr)
     } else if e == nil && p.servers[addr].disabled {
         p.lock.Lock()
         p.servers[addr].disabled = false
         p.hashRing.AddNode(addr, p.servers[addr].weight)
         p.lock.Unlock()
+        pgo.App.GetEventBus().Trigger(pgo.EventPoolUp, nil, "memcache", addr)
     }
 
     if e == nil {
@@ -283,7 +308,12 @@ func (p *Pool) probeServer(addr string) {
 
 func (p *Pool) probeLoop() {
     for {
-        <-time.After(p.probeInterval)
+        select {
+        case <-time.After(p.probeInterval):
+        case <-p.stop:
+            return
+        }
+
         for addr := range p.servers {
             p.probeServer(addr)
         }
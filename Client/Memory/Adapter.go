@@ -127,6 +127,15 @@ func (a *Adapter) MDel(keys []string) bool {
     return a.client.MDel(keys)
 }
 
+func (a *Adapter) DelPrefix(prefix string) int {
+    profile := "Memory.DelPrefix"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.DelPrefix(prefix)
+}
+
 func (a *Adapter) Exists(key string) bool {
     profile := "Memory.Exists"
     a.GetContext().ProfileStart(profile)
@@ -144,3 +153,63 @@ func (a *Adapter) Incr(key string, delta int) int {
 
     return a.client.Incr(key, delta)
 }
+
+func (a *Adapter) IncrWithExpire(key string, delta int, expire time.Duration) int {
+    profile := "Memory.IncrWithExpire"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.IncrWithExpire(key, delta, expire)
+}
+
+func (a *Adapter) Expire(key string, expire time.Duration) bool {
+    profile := "Memory.Expire"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.Expire(key, expire)
+}
+
+func (a *Adapter) TTL(key string) (time.Duration, bool) {
+    profile := "Memory.TTL"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.TTL(key)
+}
+
+func (a *Adapter) Touch(key string, expire time.Duration) *pgo.Value {
+    profile := "Memory.Touch"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    res, hit := a.client.Touch(key, expire), 0
+    if res != nil && res.Valid() {
+        hit = 1
+    }
+
+    a.GetContext().Counting(profile, hit, 1)
+    return res
+}
+
+func (a *Adapter) GetSet(key string, value interface{}, expire ...time.Duration) *pgo.Value {
+    profile := "Memory.GetSet"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.GetSet(key, value, expire...)
+}
+
+func (a *Adapter) CompareAndSwap(key string, old, new interface{}, expire ...time.Duration) bool {
+    profile := "Memory.CompareAndSwap"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.CompareAndSwap(key, old, new, expire...)
+}
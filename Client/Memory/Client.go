@@ -1,45 +1,119 @@
 package Memory
 
 import (
+    "bytes"
     "fmt"
+    "hash/fnv"
+    "strings"
     "sync"
+    "sync/atomic"
     "time"
 
     "github.com/pinguo/pgo"
     "github.com/pinguo/pgo/Util"
 )
 
-type item struct {
-    value  interface{}
-    expire time.Time
+// EvictFunc callback of evicted item, reason is EvictCapacity or EvictExpired
+type EvictFunc func(key string, value interface{}, reason string)
+
+// Stats statistics of memory cache
+type Stats struct {
+    Hits        uint64 // num of hit of Get/MGet
+    Misses      uint64 // num of miss of Get/MGet
+    Evictions   uint64 // num of items evicted by capacity limits
+    Expirations uint64 // num of expired items removed
+    Items       int    // current num of items
+    Size        int64  // current approximate bytes of items, 0 if no maxSize
 }
 
-func (i item) isExpired() bool {
-    return !i.expire.IsZero() && time.Since(i.expire) > 0
-}
-
-// Memory Client component, configuration:
+// Memory Client component, items are distributed to shards by key
+// hash, each shard has its own lock and limits(maxItems and maxSize
+// divided by shards), items are evicted by policy(lru or lfu) if
+// limits exceeded, 0 means no limit, size of item is approximate and
+// item larger than maxSize of shard is not stored.
+// expired items are removed on access or by gc loop. if snapshotFile
+// is set, non-expired items are saved to it on stop and periodically
+// if snapshotInterval is not zero, and loaded from it on init.
+// configuration:
 // memory:
 //     class: "@pgo/Client/Memory/Client"
 //     gcInterval: "60s"
 //     gcMaxItems: 1000
+//     shards: 16
+//     policy: "lru"
+//     maxItems: 0
+//     maxSize: "0"
+//     snapshotFile: "@runtime/memory.snapshot"
+//     snapshotInterval: "0s"
 type Client struct {
-    lock       sync.RWMutex
-    items      map[string]*item
+    hits        uint64
+    misses      uint64
+    evictions   uint64
+    expirations uint64
+
+    shards     []*shard
+    numShards  int
+    policy     string
+    maxItems   int
+    maxSize    int64
     gcInterval time.Duration
     gcMaxItems int
+    onEvict    EvictFunc
+
+    snapshotFile     string
+    snapshotInterval time.Duration
+    snapshotLock     sync.Mutex
+
+    stop     chan struct{}
+    stopOnce sync.Once
 }
 
 func (c *Client) Construct() {
-    c.items = make(map[string]*item)
+    c.stop = make(chan struct{})
+    c.numShards = defaultShards
+    c.policy = PolicyLru
     c.gcInterval = defaultGcInterval
     c.gcMaxItems = defaultGcMaxItems
 }
 
 func (c *Client) Init() {
+    maxItems, maxSize := 0, int64(0)
+    if c.maxItems > 0 {
+        maxItems = (c.maxItems + c.numShards - 1) / c.numShards
+    }
+
+    if c.maxSize > 0 {
+        maxSize = (c.maxSize + int64(c.numShards) - 1) / int64(c.numShards)
+    }
+
+    c.shards = make([]*shard, c.numShards)
+    for i := range c.shards {
+        c.shards[i] = newShard(c.policy, maxItems, maxSize)
+    }
+
+    if len(c.snapshotFile) > 0 {
+        if e := c.loadSnapshot(); e != nil {
+            pgo.GLogger().Error("memory: failed to load snapshot, %s", e)
+        }
+
+        if c.snapshotInterval > 0 {
+            go c.snapshotLoop()
+        }
+    }
+
     go c.gcLoop()
 }
 
+// Stop stop gc loop and save snapshot if snapshot file is set
+func (c *Client) Stop() (err error) {
+    c.stopOnce.Do(func() {
+        close(c.stop)
+        err = c.Snapshot()
+    })
+
+    return err
+}
+
 func (c *Client) SetGcInterval(v string) {
     if gcInterval, e := time.ParseDuration(v); e != nil {
         panic(fmt.Sprintf(errSetProp, "gcInterval", e.Error()))
@@ -54,86 +128,114 @@ func (c *Client) SetGcMaxItems(gcMaxItems int) {
     }
 }
 
-func (c *Client) Get(key string) *pgo.Value {
-    c.lock.RLock()
-    defer c.lock.RUnlock()
+// SetShards set num of shards, default 16
+func (c *Client) SetShards(shards int) {
+    if shards > 0 {
+        c.numShards = shards
+    }
+}
 
-    if item := c.items[key]; item != nil && !item.isExpired() {
-        return pgo.NewValue(item.value)
+// SetPolicy set eviction policy, lru or lfu, default "lru"
+func (c *Client) SetPolicy(policy string) {
+    if policy != PolicyLru && policy != PolicyLfu {
+        panic(fmt.Sprintf(errSetProp, "policy", "invalid policy "+policy))
     }
 
-    return pgo.NewValue(nil)
+    c.policy = policy
 }
 
-func (c *Client) MGet(keys []string) map[string]*pgo.Value {
-    c.lock.RLock()
-    defer c.lock.RUnlock()
+// SetMaxItems set max num of items, default 0(no limit)
+func (c *Client) SetMaxItems(maxItems int) {
+    c.maxItems = maxItems
+}
 
-    result := make(map[string]*pgo.Value)
-    for _, key := range keys {
-        if item := c.items[key]; item != nil && !item.isExpired() {
-            result[key] = pgo.NewValue(item.value)
-        } else {
-            result[key] = pgo.NewValue(nil)
+// SetMaxSize set max approximate size of items, eg. "512MB", default 0(no limit)
+func (c *Client) SetMaxSize(v interface{}) {
+    if s, ok := v.(string); ok {
+        size, e := Util.ParseSize(s)
+        if e != nil {
+            panic(fmt.Sprintf(errSetProp, "maxSize", e.Error()))
         }
+
+        c.maxSize = size
+    } else {
+        c.maxSize = int64(Util.ToInt(v))
     }
+}
 
-    return result
+// OnEvict set callback of evicted and expired items,
+// callback is called without lock held.
+func (c *Client) OnEvict(fn EvictFunc) {
+    c.onEvict = fn
 }
 
-func (c *Client) Set(key string, value interface{}, expire ...time.Duration) bool {
-    c.lock.Lock()
-    defer c.lock.Unlock()
+// GetStats get statistics of memory cache
+func (c *Client) GetStats() Stats {
+    stats := Stats{
+        Hits:        atomic.LoadUint64(&c.hits),
+        Misses:      atomic.LoadUint64(&c.misses),
+        Evictions:   atomic.LoadUint64(&c.evictions),
+        Expirations: atomic.LoadUint64(&c.expirations),
+    }
 
-    expire, now := append(expire, defaultExpire), time.Now()
-    c.items[key] = &item{
-        value:  value,
-        expire: now.Add(expire[0]),
+    for _, s := range c.shards {
+        s.lock.Lock()
+        stats.Items += len(s.items)
+        stats.Size += s.size
+        s.lock.Unlock()
+    }
+
+    return stats
+}
+
+func (c *Client) Get(key string) *pgo.Value {
+    s := c.getShard(key)
+    s.lock.Lock()
+    i, evictions := s.get(key)
+    var value interface{}
+    if i != nil {
+        value = i.value
+    }
+    s.lock.Unlock()
+
+    c.countHit(i != nil)
+    c.notify(evictions)
+    return pgo.NewValue(value)
+}
+
+func (c *Client) MGet(keys []string) map[string]*pgo.Value {
+    result := make(map[string]*pgo.Value)
+    for _, key := range keys {
+        result[key] = c.Get(key)
     }
 
+    return result
+}
+
+func (c *Client) Set(key string, value interface{}, expire ...time.Duration) bool {
+    expire = append(expire, defaultExpire)
+    c.set(key, value, time.Now().Add(expire[0]))
     return true
 }
 
 func (c *Client) MSet(items map[string]interface{}, expire ...time.Duration) bool {
-    c.lock.Lock()
-    defer c.lock.Unlock()
-
     expire, now := append(expire, defaultExpire), time.Now()
     for key, value := range items {
-        c.items[key] = &item{
-            value:  value,
-            expire: now.Add(expire[0]),
-        }
+        c.set(key, value, now.Add(expire[0]))
     }
+
     return true
 }
 
 func (c *Client) Add(key string, value interface{}, expire ...time.Duration) bool {
-    c.lock.Lock()
-    defer c.lock.Unlock()
-
-    expire, now := append(expire, defaultExpire), time.Now()
-    if old := c.items[key]; old == nil || old.isExpired() {
-        c.items[key] = &item{
-            value:  value,
-            expire: now.Add(expire[0]),
-        }
-        return true
-    }
-    return false
+    expire = append(expire, defaultExpire)
+    return c.add(key, value, time.Now().Add(expire[0]))
 }
 
 func (c *Client) MAdd(items map[string]interface{}, expire ...time.Duration) bool {
-    c.lock.Lock()
-    defer c.lock.Unlock()
-
     expire, now, success := append(expire, defaultExpire), time.Now(), 0
     for key, value := range items {
-        if old := c.items[key]; old == nil || old.isExpired() {
-            c.items[key] = &item{
-                value:  value,
-                expire: now.Add(expire[0]),
-            }
+        if c.add(key, value, now.Add(expire[0])) {
             success++
         }
     }
@@ -142,24 +244,22 @@ func (c *Client) MAdd(items map[string]interface{}, expire ...time.Duration) boo
 }
 
 func (c *Client) Del(key string) bool {
-    c.lock.Lock()
-    defer c.lock.Unlock()
+    s := c.getShard(key)
+    s.lock.Lock()
+    defer s.lock.Unlock()
 
-    if _, ok := c.items[key]; ok {
-        delete(c.items, key)
+    if i := s.items[key]; i != nil {
+        s.remove(i)
         return true
     }
+
     return false
 }
 
 func (c *Client) MDel(keys []string) bool {
-    c.lock.Lock()
-    defer c.lock.Unlock()
-
     success := 0
     for _, key := range keys {
-        if _, ok := c.items[key]; ok {
-            delete(c.items, key)
+        if c.Del(key) {
             success++
         }
     }
@@ -167,63 +267,239 @@ func (c *Client) MDel(keys []string) bool {
     return success == len(keys)
 }
 
+// DelPrefix delete all keys with prefix, number of deleted keys is returned
+func (c *Client) DelPrefix(prefix string) int {
+    n := 0
+    for _, s := range c.shards {
+        s.lock.Lock()
+        for key, i := range s.items {
+            if strings.HasPrefix(key, prefix) {
+                s.remove(i)
+                n++
+            }
+        }
+        s.lock.Unlock()
+    }
+
+    return n
+}
+
 func (c *Client) Exists(key string) bool {
-    c.lock.RLock()
-    defer c.lock.RUnlock()
+    s := c.getShard(key)
+    s.lock.Lock()
+    defer s.lock.Unlock()
 
-    _, ok := c.items[key]
-    return ok
+    i := s.items[key]
+    return i != nil && !i.isExpired()
 }
 
+// Incr increase value of key, key not exists
+// or expired is created without expire.
 func (c *Client) Incr(key string, delta int) int {
-    c.lock.Lock()
-    defer c.lock.Unlock()
+    return c.IncrWithExpire(key, delta, 0)
+}
 
-    cur := c.items[key]
+// IncrWithExpire increase value of key, key not exists or
+// expired is created with expire, 0 means never expire,
+// panic if counter is too large to store in shard.
+func (c *Client) IncrWithExpire(key string, delta int, expire time.Duration) int {
+    s := c.getShard(key)
+    s.lock.Lock()
+
+    cur, evictions := s.get(key)
     if cur == nil {
-        cur = &item{value: 0}
-        c.items[key] = cur
+        cur = &item{key: key, value: 0, expire: expireAt(expire), size: s.sizeOf(key, 0)}
+        if evictions = s.set(cur, evictions); s.items[key] != cur {
+            s.lock.Unlock()
+            c.notify(evictions)
+            panic(fmt.Sprintf(errTooLarge, key))
+        }
     }
 
     newVal := Util.ToInt(cur.value) + delta
     cur.value = newVal
+    s.resize(cur)
+    s.lock.Unlock()
+
+    c.notify(evictions)
     return newVal
 }
 
-func (c *Client) gcLoop() {
-    if c.gcInterval < minGcInterval || c.gcInterval > maxGcInterval {
-        c.gcInterval = defaultGcInterval
+// Expire set expire of key, 0 means never expire,
+// false is returned if key not exists.
+func (c *Client) Expire(key string, expire time.Duration) bool {
+    s := c.getShard(key)
+    s.lock.Lock()
+    i, evictions := s.get(key)
+    if i != nil {
+        i.expire = expireAt(expire)
     }
+    s.lock.Unlock()
 
-    getExpireKeys := func() []string {
-        c.lock.RLock()
-        defer c.lock.RUnlock()
+    c.notify(evictions)
+    return i != nil
+}
 
-        keys, now := make([]string, 0), time.Now()
-        for key, item := range c.items {
-            if !item.expire.IsZero() && item.expire.Sub(now) < 0 {
-                keys = append(keys, key)
-                if len(keys) >= c.gcMaxItems {
-                    break
-                }
-            }
+// TTL get remaining ttl of key, 0 means never expire,
+// ok is false if key not exists.
+func (c *Client) TTL(key string) (time.Duration, bool) {
+    s := c.getShard(key)
+    s.lock.Lock()
+    i, evictions := s.get(key)
+    ttl := time.Duration(0)
+    if i != nil && !i.expire.IsZero() {
+        ttl = time.Until(i.expire)
+    }
+    s.lock.Unlock()
+
+    c.notify(evictions)
+    return ttl, i != nil
+}
+
+// Touch get value of key and set expire of key, 0 means never expire
+func (c *Client) Touch(key string, expire time.Duration) *pgo.Value {
+    s := c.getShard(key)
+    s.lock.Lock()
+    i, evictions := s.get(key)
+    var value interface{}
+    if i != nil {
+        i.expire, value = expireAt(expire), i.value
+    }
+    s.lock.Unlock()
+
+    c.countHit(i != nil)
+    c.notify(evictions)
+    return pgo.NewValue(value)
+}
+
+// GetSet set value of key and return the old value, 0 means never expire
+func (c *Client) GetSet(key string, value interface{}, expire ...time.Duration) *pgo.Value {
+    expire, s := append(expire, defaultExpire), c.getShard(key)
+    i := &item{key: key, value: value, expire: expireAt(expire[0]), size: s.sizeOf(key, value)}
+
+    s.lock.Lock()
+    old, evictions := s.get(key)
+    var oldValue interface{}
+    if old != nil {
+        oldValue = old.value
+    }
+
+    evictions = s.set(i, evictions)
+    s.lock.Unlock()
+
+    c.notify(evictions)
+    return pgo.NewValue(oldValue)
+}
+
+// CompareAndSwap set value of key if encoded value of key equals
+// to encoded old, false if key not exists, 0 means never expire.
+func (c *Client) CompareAndSwap(key string, old, new interface{}, expire ...time.Duration) bool {
+    expire, oldData, s := append(expire, defaultExpire), pgo.Encode(old), c.getShard(key)
+    i := &item{key: key, value: new, expire: expireAt(expire[0]), size: s.sizeOf(key, new)}
+
+    s.lock.Lock()
+    cur, evictions := s.get(key)
+    swapped := false
+    if cur != nil {
+        if data, e := pgo.NewValue(cur.value).TryEncode(); e == nil && bytes.Equal(data, oldData) {
+            evictions, swapped = s.set(i, evictions), true
         }
-        return keys
     }
+    s.lock.Unlock()
 
-    clearExpiredKeys := func(keys []string) {
-        c.lock.Lock()
-        defer c.lock.Unlock()
+    c.notify(evictions)
+    return swapped
+}
+
+func (c *Client) set(key string, value interface{}, expire time.Time) {
+    s := c.getShard(key)
+    i := &item{key: key, value: value, expire: expire, size: s.sizeOf(key, value)}
+    s.lock.Lock()
+    evictions := s.set(i, nil)
+    s.lock.Unlock()
+
+    c.notify(evictions)
+}
 
-        for _, key := range keys {
-            delete(c.items, key)
+func (c *Client) add(key string, value interface{}, expire time.Time) bool {
+    s := c.getShard(key)
+    i := &item{key: key, value: value, expire: expire, size: s.sizeOf(key, value)}
+    s.lock.Lock()
+    if old := s.items[key]; old != nil && !old.isExpired() {
+        s.lock.Unlock()
+        return false
+    }
+
+    evictions := s.set(i, nil)
+    s.lock.Unlock()
+
+    c.notify(evictions)
+    return true
+}
+
+// expireAt get expire time of duration, zero time if d is 0
+func expireAt(d time.Duration) time.Time {
+    if d == 0 {
+        return time.Time{}
+    }
+
+    return time.Now().Add(d)
+}
+
+func (c *Client) getShard(key string) *shard {
+    h := fnv.New32a()
+    h.Write([]byte(key))
+    return c.shards[h.Sum32()%uint32(len(c.shards))]
+}
+
+func (c *Client) countHit(hit bool) {
+    if hit {
+        atomic.AddUint64(&c.hits, 1)
+    } else {
+        atomic.AddUint64(&c.misses, 1)
+    }
+}
+
+// notify count evictions and call callback of evicted items
+func (c *Client) notify(evictions []eviction) {
+    for _, ev := range evictions {
+        if ev.reason == EvictExpired {
+            atomic.AddUint64(&c.expirations, 1)
+        } else {
+            atomic.AddUint64(&c.evictions, 1)
+        }
+
+        if c.onEvict != nil {
+            c.callEvict(ev)
         }
     }
+}
+
+// call evict callback, panic of callback is logged
+func (c *Client) callEvict(ev eviction) {
+    defer func() {
+        if v := recover(); v != nil {
+            pgo.GLogger().Error("memory: failed to call evict callback, %s", Util.ToString(v))
+        }
+    }()
+
+    c.onEvict(ev.item.key, ev.item.value, ev.reason)
+}
+
+func (c *Client) gcLoop() {
+    if c.gcInterval < minGcInterval || c.gcInterval > maxGcInterval {
+        c.gcInterval = defaultGcInterval
+    }
 
     for {
-        <-time.After(c.gcInterval)
-        if expiredKeys := getExpireKeys(); len(expiredKeys) > 0 {
-            clearExpiredKeys(expiredKeys)
+        select {
+        case <-time.After(c.gcInterval):
+        case <-c.stop:
+            return
+        }
+
+        for _, s := range c.shards {
+            c.notify(s.clearExpired(c.gcMaxItems))
         }
     }
 }
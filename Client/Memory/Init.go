@@ -13,11 +13,22 @@ const (
     defaultGcMaxItems  = 1000
     defaultGcInterval  = 60 * time.Second
     defaultExpire      = 60 * time.Second
+    defaultShards      = 16
+
+    // approximate memory overhead of item
+    itemOverhead = 64
 
     minGcInterval = 10 * time.Second
     maxGcInterval = 600 * time.Second
 
-    errSetProp = "memory: failed to set %s, %s"
+    errSetProp  = "memory: failed to set %s, %s"
+    errTooLarge = "memory: item of key %s is larger than maxSize of shard"
+
+    PolicyLru = "lru"
+    PolicyLfu = "lfu"
+
+    EvictCapacity = "capacity"
+    EvictExpired  = "expired"
 )
 
 func init() {
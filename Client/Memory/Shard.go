@@ -0,0 +1,260 @@
+package Memory
+
+import (
+    "container/heap"
+    "container/list"
+    "sync"
+    "time"
+
+    "github.com/pinguo/pgo"
+)
+
+type item struct {
+    key    string
+    value  interface{}
+    expire time.Time
+    size   int64
+
+    elem  *list.Element // element of lru list
+    index int           // index of lfu heap
+    freq  int           // access frequency of lfu
+    tick  uint64        // last access tick of lfu
+}
+
+func (i *item) isExpired() bool {
+    return !i.expire.IsZero() && time.Since(i.expire) > 0
+}
+
+// evicted item and reason
+type eviction struct {
+    item   *item
+    reason string
+}
+
+// policy eviction policy of shard
+type policy interface {
+    add(i *item)
+    access(i *item)
+    remove(i *item)
+    victim() *item
+}
+
+// lruPolicy evict the least recently used item
+type lruPolicy struct {
+    list *list.List
+}
+
+func (p *lruPolicy) add(i *item) {
+    i.elem = p.list.PushFront(i)
+}
+
+func (p *lruPolicy) access(i *item) {
+    p.list.MoveToFront(i.elem)
+}
+
+func (p *lruPolicy) remove(i *item) {
+    p.list.Remove(i.elem)
+}
+
+func (p *lruPolicy) victim() *item {
+    if e := p.list.Back(); e != nil {
+        return e.Value.(*item)
+    }
+
+    return nil
+}
+
+// lfuPolicy evict the least frequently used item,
+// the least recently used one of the same frequency.
+type lfuPolicy struct {
+    items lfuHeap
+    tick  uint64
+}
+
+func (p *lfuPolicy) add(i *item) {
+    p.tick++
+    i.freq, i.tick = 1, p.tick
+    heap.Push(&p.items, i)
+}
+
+func (p *lfuPolicy) access(i *item) {
+    p.tick++
+    i.freq, i.tick = i.freq+1, p.tick
+    heap.Fix(&p.items, i.index)
+}
+
+func (p *lfuPolicy) remove(i *item) {
+    heap.Remove(&p.items, i.index)
+}
+
+func (p *lfuPolicy) victim() *item {
+    if len(p.items) > 0 {
+        return p.items[0]
+    }
+
+    return nil
+}
+
+type lfuHeap []*item
+
+func (h lfuHeap) Len() int {
+    return len(h)
+}
+
+func (h lfuHeap) Less(i, j int) bool {
+    if h[i].freq != h[j].freq {
+        return h[i].freq < h[j].freq
+    }
+
+    return h[i].tick < h[j].tick
+}
+
+func (h lfuHeap) Swap(i, j int) {
+    h[i], h[j] = h[j], h[i]
+    h[i].index, h[j].index = i, j
+}
+
+func (h *lfuHeap) Push(x interface{}) {
+    i := x.(*item)
+    i.index = len(*h)
+    *h = append(*h, i)
+}
+
+func (h *lfuHeap) Pop() interface{} {
+    old, n := *h, len(*h)
+    i := old[n-1]
+    old[n-1] = nil
+    *h = old[:n-1]
+    return i
+}
+
+// shard part of items with its own lock and limits
+type shard struct {
+    lock     sync.Mutex
+    items    map[string]*item
+    policy   policy
+    size     int64
+    maxItems int
+    maxSize  int64
+}
+
+func newShard(policyName string, maxItems int, maxSize int64) *shard {
+    s := &shard{
+        items:    make(map[string]*item),
+        maxItems: maxItems,
+        maxSize:  maxSize,
+    }
+
+    if policyName == PolicyLfu {
+        s.policy = &lfuPolicy{}
+    } else {
+        s.policy = &lruPolicy{list: list.New()}
+    }
+
+    return s
+}
+
+// get item of key, expired item is removed and returned as eviction
+func (s *shard) get(key string) (*item, []eviction) {
+    i := s.items[key]
+    if i == nil {
+        return nil, nil
+    } else if i.isExpired() {
+        s.remove(i)
+        return nil, []eviction{{i, EvictExpired}}
+    }
+
+    s.policy.access(i)
+    return i, nil
+}
+
+// set item, old item of the same key is replaced, items are
+// evicted by policy before setting if limits would be exceeded,
+// item larger than maxSize is not stored and evicted at once.
+func (s *shard) set(i *item, evictions []eviction) []eviction {
+    if old := s.items[i.key]; old != nil {
+        s.remove(old)
+    }
+
+    if s.maxSize > 0 && i.size > s.maxSize {
+        return append(evictions, eviction{i, EvictCapacity})
+    }
+
+    for len(s.items) > 0 && ((s.maxItems > 0 && len(s.items) >= s.maxItems) ||
+        (s.maxSize > 0 && s.size+i.size > s.maxSize)) {
+        victim := s.policy.victim()
+        s.remove(victim)
+        evictions = append(evictions, eviction{victim, EvictCapacity})
+    }
+
+    s.items[i.key] = i
+    s.size += i.size
+    s.policy.add(i)
+
+    return evictions
+}
+
+// resize update size of stored item after value changed
+func (s *shard) resize(i *item) {
+    if s.maxSize == 0 || s.items[i.key] != i {
+        return
+    }
+
+    size := sizeOf(i.key, i.value)
+    s.size += size - i.size
+    i.size = size
+}
+
+func (s *shard) remove(i *item) {
+    s.policy.remove(i)
+    delete(s.items, i.key)
+    s.size -= i.size
+}
+
+// clearExpired remove at most max expired items
+func (s *shard) clearExpired(max int) []eviction {
+    s.lock.Lock()
+    defer s.lock.Unlock()
+
+    evictions, now := make([]eviction, 0), time.Now()
+    for _, i := range s.items {
+        if !i.expire.IsZero() && i.expire.Sub(now) < 0 {
+            s.remove(i)
+            evictions = append(evictions, eviction{i, EvictExpired})
+            if len(evictions) >= max {
+                break
+            }
+        }
+    }
+
+    return evictions
+}
+
+// sizeOf get approximate size of item, size is
+// not computed if shard has no size limit.
+func (s *shard) sizeOf(key string, value interface{}) int64 {
+    if s.maxSize == 0 {
+        return 0
+    }
+
+    return sizeOf(key, value)
+}
+
+// sizeOf get approximate size of item
+func sizeOf(key string, value interface{}) int64 {
+    size := int64(len(key) + itemOverhead)
+    switch v := value.(type) {
+    case []byte:
+        size += int64(len(v))
+    case string:
+        size += int64(len(v))
+    case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+        size += 8
+    default:
+        if b, e := pgo.NewValue(value).TryEncode(); e == nil {
+            size += int64(len(b))
+        }
+    }
+
+    return size
+}
@@ -0,0 +1,195 @@
+package Memory
+
+import (
+    "bufio"
+    "encoding/gob"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "strconv"
+    "time"
+
+    "github.com/pinguo/pgo"
+)
+
+// kind of snapshot value
+const (
+    kindBytes = iota
+    kindString
+    kindBool
+    kindInt
+    kindFloat
+    kindOther
+)
+
+// snapshotItem item saved in snapshot file, value is encoded by
+// pgo.Encode, values of []byte, string, bool, int and float64 are
+// restored to original type, values of other types are restored as
+// encoded bytes(json), which can be decoded by pgo.Value.Decode.
+type snapshotItem struct {
+    Key    string
+    Kind   uint8
+    Value  []byte
+    Expire time.Time
+}
+
+// SetSnapshotFile set snapshot file, eg. "@runtime/memory.snapshot",
+// default "" means snapshot is disabled.
+func (c *Client) SetSnapshotFile(v string) {
+    if len(v) > 0 {
+        c.snapshotFile = pgo.GetAlias(v)
+    } else {
+        c.snapshotFile = ""
+    }
+}
+
+// SetSnapshotInterval set interval of periodic snapshot,
+// default "0s" means snapshot on stop only.
+func (c *Client) SetSnapshotInterval(v string) {
+    if interval, e := time.ParseDuration(v); e != nil {
+        panic(fmt.Sprintf(errSetProp, "snapshotInterval", e.Error()))
+    } else {
+        c.snapshotInterval = interval
+    }
+}
+
+// Snapshot save non-expired items to snapshot file,
+// file is written to temp file and renamed.
+func (c *Client) Snapshot() error {
+    if len(c.snapshotFile) == 0 {
+        return nil
+    }
+
+    c.snapshotLock.Lock()
+    defer c.snapshotLock.Unlock()
+
+    if e := os.MkdirAll(filepath.Dir(c.snapshotFile), 0755); e != nil {
+        return e
+    }
+
+    tmpFile := c.snapshotFile + ".tmp"
+    h, e := os.Create(tmpFile)
+    if e != nil {
+        return e
+    }
+
+    w := bufio.NewWriter(h)
+    if e = c.writeSnapshot(w); e == nil {
+        e = w.Flush()
+    }
+
+    if e2 := h.Close(); e == nil {
+        e = e2
+    }
+
+    if e != nil {
+        os.Remove(tmpFile)
+        return e
+    }
+
+    return os.Rename(tmpFile, c.snapshotFile)
+}
+
+func (c *Client) writeSnapshot(w *bufio.Writer) error {
+    enc, now := gob.NewEncoder(w), time.Now()
+    for _, s := range c.shards {
+        // copy items of shard to avoid holding lock while encoding
+        s.lock.Lock()
+        items := make([]item, 0, len(s.items))
+        for _, i := range s.items {
+            if i.expire.IsZero() || i.expire.After(now) {
+                items = append(items, item{key: i.key, value: i.value, expire: i.expire})
+            }
+        }
+        s.lock.Unlock()
+
+        for _, i := range items {
+            si := snapshotItem{Key: i.key, Expire: i.expire}
+            switch v := i.value.(type) {
+            case []byte:
+                si.Kind, si.Value = kindBytes, v
+            case string:
+                si.Kind, si.Value = kindString, []byte(v)
+            case bool:
+                si.Kind, si.Value = kindBool, pgo.Encode(v)
+            case int:
+                si.Kind, si.Value = kindInt, pgo.Encode(v)
+            case float64:
+                si.Kind, si.Value = kindFloat, pgo.Encode(v)
+            default:
+                value, e := pgo.NewValue(v).TryEncode()
+                if e != nil {
+                    continue
+                }
+
+                si.Kind, si.Value = kindOther, value
+            }
+
+            if e := enc.Encode(&si); e != nil {
+                return e
+            }
+        }
+    }
+
+    return nil
+}
+
+// loadSnapshot load items from snapshot file, expired items are skipped
+func (c *Client) loadSnapshot() error {
+    h, e := os.Open(c.snapshotFile)
+    if os.IsNotExist(e) {
+        return nil
+    } else if e != nil {
+        return e
+    }
+
+    defer h.Close()
+
+    dec, now, n := gob.NewDecoder(bufio.NewReader(h)), time.Now(), 0
+    for {
+        var si snapshotItem
+        if e := dec.Decode(&si); e != nil {
+            if e == io.EOF {
+                break
+            }
+
+            return fmt.Errorf("memory: corrupted snapshot after %d items, %s", n, e)
+        }
+
+        if !si.Expire.IsZero() && !si.Expire.After(now) {
+            continue
+        }
+
+        var value interface{} = si.Value
+        switch si.Kind {
+        case kindString:
+            value = string(si.Value)
+        case kindBool:
+            value, _ = strconv.ParseBool(string(si.Value))
+        case kindInt:
+            value, _ = strconv.Atoi(string(si.Value))
+        case kindFloat:
+            value, _ = strconv.ParseFloat(string(si.Value), 64)
+        }
+
+        c.set(si.Key, value, si.Expire)
+        n++
+    }
+
+    return nil
+}
+
+func (c *Client) snapshotLoop() {
+    for {
+        select {
+        case <-time.After(c.snapshotInterval):
+        case <-c.stop:
+            return
+        }
+
+        if e := c.Snapshot(); e != nil {
+            pgo.GLogger().Error("memory: failed to save snapshot, %s", e)
+        }
+    }
+}
@@ -4,6 +4,7 @@ import (
     "fmt"
     "net/url"
     "strings"
+    "sync"
     "time"
 
     "github.com/globalsign/mgo"
@@ -29,7 +30,9 @@ import (
 // wtimeoutMS=10000
 // readPreference=secondaryPreferred
 type Client struct {
+    lock           sync.Mutex
     session        *mgo.Session
+    dialInfo       *mgo.DialInfo
     dsn            string
     connectTimeout time.Duration
     readTimeout    time.Duration
@@ -67,12 +70,42 @@ func (c *Client) Init() {
     dialInfo.Timeout = c.connectTimeout
     dialInfo.ReadTimeout = c.readTimeout
     dialInfo.WriteTimeout = c.writeTimeout
+    c.dialInfo = dialInfo
+}
+
+// Start dial to mongo servers, client not started
+// dials on first call of GetSession.
+func (c *Client) Start() error {
+    _, e := c.dial()
+    return e
+}
+
+// Stop close session
+func (c *Client) Stop() error {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    if c.session != nil {
+        c.session.Close()
+    }
+
+    return nil
+}
 
-    if c.session, e = mgo.DialWithInfo(dialInfo); e != nil {
-        panic(fmt.Sprintf(errDialFailed, c.dsn, e.Error()))
+// HealthCheck ping mongo servers
+func (c *Client) HealthCheck() error {
+    c.lock.Lock()
+    session := c.session
+    c.lock.Unlock()
+
+    if session == nil {
+        return fmt.Errorf("mongo: client is not started")
     }
 
-    c.session.SetMode(mgo.Monotonic, true)
+    session = session.Copy()
+    defer session.Close()
+
+    return session.Ping()
 }
 
 func (c *Client) SetDsn(dsn string) {
@@ -104,5 +137,29 @@ func (c *Client) SetWriteTimeout(v string) {
 }
 
 func (c *Client) GetSession() *mgo.Session {
-    return c.session.Copy()
+    session, e := c.dial()
+    if e != nil {
+        panic(e.Error())
+    }
+
+    return session.Copy()
+}
+
+// dial dial to mongo servers if not dialed, the
+// session is kept for copying by GetSession.
+func (c *Client) dial() (*mgo.Session, error) {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    if c.session == nil {
+        session, e := mgo.DialWithInfo(c.dialInfo)
+        if e != nil {
+            return nil, fmt.Errorf(errDialFailed, c.dsn, e.Error())
+        }
+
+        session.SetMode(mgo.Monotonic, true)
+        c.session = session
+    }
+
+    return c.session, nil
 }
@@ -60,6 +60,20 @@ func (a *Adapter) Publish(opCode string, data interface{}, dftOpUid ...string) b
     return a.client.publish(&PublishData{OpCode: opCode, Data: data, OpUid: opUid}, a.GetContext().GetLogId())
 }
 
+// PublishE publish message, error is returned instead of panic
+func (a *Adapter) PublishE(opCode string, data interface{}, dftOpUid ...string) error {
+    profile := "rabbit.Publish"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+
+    opUid := ""
+    if len(dftOpUid) > 0 {
+        opUid = dftOpUid[0]
+    }
+
+    return a.client.publishE(&PublishData{OpCode: opCode, Data: data, OpUid: opUid}, a.GetContext().GetLogId())
+}
+
 func (a *Adapter) GetConsumeChannelBox(queueName string, opCodes []string) *ChannelBox {
     profile := "rabbit.GetConsumeChannelBox"
     a.GetContext().ProfileStart(profile)
@@ -3,8 +3,10 @@ package RabbitMq
 import (
     "bytes"
     "encoding/gob"
+    "fmt"
     "time"
 
+    "github.com/pinguo/pgo"
     "github.com/streadway/amqp"
 )
 
@@ -70,11 +72,27 @@ func (c *Client) setExchangeDeclare() {
     c.exchangeDeclare(ch)
 }
 
-func (c *Client) publish(parameter *PublishData, logId string) bool{
+func (c *Client) publish(parameter *PublishData, logId string) bool {
+    if e := c.publishE(parameter, logId); e != nil {
+        panic(e)
+    }
+
+    return true
+}
+
+// publishE publish message, error is returned instead of panic
+func (c *Client) publishE(parameter *PublishData, logId string) (err error) {
     if parameter.OpCode == "" || parameter.Data == nil {
-        panic("Rabbit OpCode and LogId cannot be empty")
+        return fmt.Errorf("Rabbit OpCode and LogId cannot be empty")
     }
 
+    // getFreeChannel panics if no connection available
+    defer func() {
+        if v := recover(); v != nil {
+            err = pgo.ToError(v)
+        }
+    }()
+
     ch := c.getFreeChannel()
     defer ch.Close(false)
 
@@ -83,10 +101,11 @@ func (c *Client) publish(parameter *PublishData, logId string) bool{
 
     var goBytes bytes.Buffer
     myGob := gob.NewEncoder(&goBytes)
-    err := myGob.Encode(parameter.Data)
-    c.failOnError(err, "Encode err")
+    if e := myGob.Encode(parameter.Data); e != nil {
+        return fmt.Errorf("Rabbit:Encode err,err:%w", e)
+    }
 
-    err = ch.channel.Publish(
+    e := ch.channel.Publish(
         c.getExchangeName(),             // exchange
         c.getRouteKey(parameter.OpCode), // routing key
         false,                           // mandatory
@@ -97,8 +116,7 @@ func (c *Client) publish(parameter *PublishData, logId string) bool{
             Headers:     amqp.Table{"logId": logId, "service": c.ServiceName, "opUid": parameter.OpUid},
             Timestamp:   time.Now(),
         })
-    c.failOnError(err, "Failed to publish a message")
-    return true
+    return c.wrapError(e, "Failed to publish a message")
 }
 
 // 定义交换机
@@ -184,7 +202,22 @@ func (c *Client) consume(parameter *ConsumeData) <-chan amqp.Delivery {
 }
 
 func (c *Client) failOnError(err error, msg string) {
-    if err != nil {
-        panic("Rabbit:" + msg + ",err:" + err.Error())
+    if e := c.wrapError(err, msg); e != nil {
+        panic(e)
+    }
+}
+
+// wrapError wrap error of amqp, kind of error is pgo.ErrNetwork
+// if connection or channel is closed.
+func (c *Client) wrapError(err error, msg string) error {
+    if err == nil {
+        return nil
+    }
+
+    var kind error
+    if err == amqp.ErrClosed {
+        kind = pgo.ErrNetwork
     }
+
+    return pgo.NewError(kind, err, "Rabbit:%s,err:%s", msg, err.Error())
 }
@@ -38,9 +38,13 @@ type Pool struct {
     connList map[string]*ConnBox
 
     lock sync.RWMutex
+
+    stop     chan struct{}
+    stopOnce sync.Once
 }
 
 func (c *Pool) Construct() {
+    c.stop = make(chan struct{})
     c.connList = make(map[string]*ConnBox)
     c.servers = make(map[string]*serverInfo)
     c.maxChannelNum = dftMaxChannelNum
@@ -72,6 +76,21 @@ func (c *Pool) Init() {
 
 }
 
+// Stop stop probe loop and close connections
+func (c *Pool) Stop() error {
+    c.stopOnce.Do(func() { close(c.stop) })
+
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    for id, connBox := range c.connList {
+        connBox.setDisable()
+        delete(c.connList, id)
+    }
+
+    return nil
+}
+
 func (c *Pool) SetServers(v []interface{}) {
     for _, vv := range v {
         addr := vv.(string)
@@ -316,7 +335,12 @@ func (c *Pool) probeServer(addr string, weight int64) {
 
 func (c *Pool) probeLoop() {
     for {
-        <-time.After(c.probeInterval)
+        select {
+        case <-time.After(c.probeInterval):
+        case <-c.stop:
+            return
+        }
+
         for addr, info := range c.servers {
             c.probeServer(addr, info.weight)
         }
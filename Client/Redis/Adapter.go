@@ -41,6 +41,13 @@ func (a *Adapter) handlePanic() {
     }
 }
 
+// recoverError recover panic as error, used by error-returning methods
+func (a *Adapter) recoverError(err *error) {
+    if v := recover(); v != nil {
+        *err = pgo.ToError(v)
+    }
+}
+
 func (a *Adapter) Get(key string) *pgo.Value {
     profile := "Redis.Get"
     a.GetContext().ProfileStart(profile)
@@ -56,6 +63,28 @@ func (a *Adapter) Get(key string) *pgo.Value {
     return res
 }
 
+// GetE get value of key, nil value and nil error are returned
+// if key not exists, error is returned on failures, eg.
+// if v, e := redis.GetE("key"); errors.Is(e, pgo.ErrTimeout) {...}
+func (a *Adapter) GetE(key string) (res *pgo.Value, err error) {
+    profile := "Redis.Get"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.recoverError(&err)
+
+    res, err = a.client.GetE(key)
+    if err == nil {
+        hit := 0
+        if res != nil {
+            hit = 1
+        }
+
+        a.GetContext().Counting(profile, hit, 1)
+    }
+
+    return res, err
+}
+
 func (a *Adapter) MGet(keys []string) map[string]*pgo.Value {
     profile := "Redis.MGet"
     a.GetContext().ProfileStart(profile)
@@ -82,6 +111,16 @@ func (a *Adapter) Set(key string, value interface{}, expire ...time.Duration) bo
     return a.client.Set(key, value, expire...)
 }
 
+// SetE set value of key, error is returned instead of panic
+func (a *Adapter) SetE(key string, value interface{}, expire ...time.Duration) (ok bool, err error) {
+    profile := "Redis.Set"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.recoverError(&err)
+
+    return a.client.SetE(key, value, expire...)
+}
+
 func (a *Adapter) MSet(items map[string]interface{}, expire ...time.Duration) bool {
     profile := "Redis.MSet"
     a.GetContext().ProfileStart(profile)
@@ -118,6 +157,16 @@ func (a *Adapter) Del(key string) bool {
     return a.client.Del(key)
 }
 
+// DelE delete key, error is returned instead of panic
+func (a *Adapter) DelE(key string) (ok bool, err error) {
+    profile := "Redis.Del"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.recoverError(&err)
+
+    return a.client.DelE(key)
+}
+
 func (a *Adapter) MDel(keys []string) bool {
     profile := "Redis.MDel"
     a.GetContext().ProfileStart(profile)
@@ -145,6 +194,71 @@ func (a *Adapter) Incr(key string, delta int) int {
     return a.client.Incr(key, delta)
 }
 
+func (a *Adapter) IncrWithExpire(key string, delta int, expire time.Duration) int {
+    profile := "Redis.IncrWithExpire"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.IncrWithExpire(key, delta, expire)
+}
+
+func (a *Adapter) Expire(key string, expire time.Duration) bool {
+    profile := "Redis.Expire"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.Expire(key, expire)
+}
+
+func (a *Adapter) TTL(key string) (time.Duration, bool) {
+    profile := "Redis.TTL"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.TTL(key)
+}
+
+func (a *Adapter) Touch(key string, expire time.Duration) *pgo.Value {
+    profile := "Redis.Touch"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    res, hit := a.client.Touch(key, expire), 0
+    if res != nil && res.Valid() {
+        hit = 1
+    }
+
+    a.GetContext().Counting(profile, hit, 1)
+    return res
+}
+
+func (a *Adapter) GetSet(key string, value interface{}, expire ...time.Duration) *pgo.Value {
+    profile := "Redis.GetSet"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.GetSet(key, value, expire...)
+}
+
+func (a *Adapter) CompareAndSwap(key string, old, new interface{}, expire ...time.Duration) bool {
+    profile := "Redis.CompareAndSwap"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.handlePanic()
+
+    return a.client.CompareAndSwap(key, old, new, expire...)
+}
+
+// NewLock create distributed lock of key, see pgo.Lock
+func (a *Adapter) NewLock(key string, ttl time.Duration) *pgo.Lock {
+    return a.client.NewLock(key, ttl)
+}
+
 // 支持的命令请查阅：Redis.allRedisCmd
 // args = [0:"key"]
 // Example:
@@ -165,3 +279,23 @@ func (a *Adapter) Do(cmd string, args ...interface{}) interface{} {
     defer a.handlePanic()
     return a.client.Do(cmd, args ...)
 }
+
+// IncrE increase value of key, error is returned instead of panic
+func (a *Adapter) IncrE(key string, delta int) (num int, err error) {
+    profile := "Redis.Incr"
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.recoverError(&err)
+
+    return a.client.IncrE(key, delta)
+}
+
+// DoE send command like Do, error is returned instead of panic
+func (a *Adapter) DoE(cmd string, args ...interface{}) (reply interface{}, err error) {
+    profile := "Redis.Do." + cmd
+    a.GetContext().ProfileStart(profile)
+    defer a.GetContext().ProfileStop(profile)
+    defer a.recoverError(&err)
+
+    return a.client.DoE(cmd, args...)
+}
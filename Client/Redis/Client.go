@@ -2,6 +2,7 @@ package Redis
 
 import (
     "bytes"
+    "fmt"
     "strings"
     "sync"
     "sync/atomic"
@@ -31,11 +32,26 @@ type Client struct {
 }
 
 func (c *Client) Get(key string) *pgo.Value {
-    newKey := c.BuildKey(key)
-    conn := c.GetConnByKey("GET", newKey)
-    defer conn.Close(false)
+    value, e := c.GetE(key)
+    if e != nil {
+        panic(e)
+    } else if value == nil {
+        return pgo.NewValue(nil)
+    }
 
-    return pgo.NewValue(conn.Do("GET", newKey))
+    return value
+}
+
+// GetE get value of key, nil value and nil error are returned
+// if key not exists, error is returned on failures, the kind of
+// error can be checked by errors.Is, eg. pgo.ErrTimeout.
+func (c *Client) GetE(key string) (*pgo.Value, error) {
+    reply, e := c.doKeyE("GET", key)
+    if e != nil || reply == nil {
+        return nil, e
+    }
+
+    return pgo.NewValue(reply), nil
 }
 
 func (c *Client) MGet(keys []string) map[string]*pgo.Value {
@@ -66,6 +82,12 @@ func (c *Client) Set(key string, value interface{}, expire ...time.Duration) boo
     return c.set(key, value, expire[0], "")
 }
 
+// SetE set value of key like Set, error is returned instead of panic
+func (c *Client) SetE(key string, value interface{}, expire ...time.Duration) (bool, error) {
+    expire = append(expire, defaultExpire)
+    return c.setE(key, value, expire[0], "")
+}
+
 func (c *Client) MSet(items map[string]interface{}, expire ...time.Duration) bool {
     expire = append(expire, defaultExpire)
     return c.mset(items, expire[0], "")
@@ -82,12 +104,19 @@ func (c *Client) MAdd(items map[string]interface{}, expire ...time.Duration) boo
 }
 
 func (c *Client) Del(key string) bool {
-    newKey := c.BuildKey(key)
-    conn := c.GetConnByKey("DEL", newKey)
-    defer conn.Close(false)
+    ok, e := c.DelE(key)
+    if e != nil {
+        panic(e)
+    }
 
-    num, ok := conn.Do("DEL", newKey).(int)
-    return ok && num == 1
+    return ok
+}
+
+// DelE delete key like Del, error is returned instead of panic
+func (c *Client) DelE(key string) (bool, error) {
+    reply, e := c.doKeyE("DEL", key)
+    num, ok := reply.(int)
+    return ok && num == 1, e
 }
 
 func (c *Client) MDel(keys []string) bool {
@@ -117,28 +146,128 @@ func (c *Client) Exists(key string) bool {
 }
 
 func (c *Client) Incr(key string, delta int) int {
-    newKey := c.BuildKey(key)
-    conn := c.GetConnByKey("INCRBY", newKey)
-    defer conn.Close(false)
+    num, e := c.IncrE(key, delta)
+    if e != nil {
+        panic(e)
+    }
 
-    num, _ := conn.Do("INCRBY", newKey, delta).(int)
     return num
 }
 
+// IncrE increase value of key like Incr, error is returned instead of panic
+func (c *Client) IncrE(key string, delta int) (int, error) {
+    reply, e := c.doKeyE("INCRBY", key, delta)
+    num, _ := reply.(int)
+    return num, e
+}
+
+// IncrWithExpire increase value of key, key not exists
+// is created with expire, 0 means never expire.
+func (c *Client) IncrWithExpire(key string, delta int, expire time.Duration) int {
+    num, _ := c.evalKey(scriptIncrWithExpire, key, delta, milliseconds(expire)).(int)
+    return num
+}
+
+// Expire set expire of key, 0 means never expire,
+// false is returned if key not exists.
+func (c *Client) Expire(key string, expire time.Duration) bool {
+    num, ok := c.evalKey(scriptExpire, key, milliseconds(expire)).(int)
+    return ok && num == 1
+}
+
+// TTL get remaining ttl of key, 0 means never expire,
+// ok is false if key not exists.
+func (c *Client) TTL(key string) (time.Duration, bool) {
+    reply, e := c.doKeyE("PTTL", key)
+    if e != nil {
+        panic(e)
+    }
+
+    switch ttl, _ := reply.(int); {
+    case ttl == -2:
+        return 0, false
+    case ttl < 0:
+        return 0, true
+    default:
+        return time.Duration(ttl) * time.Millisecond, true
+    }
+}
+
+// Touch get value of key and set expire of key, 0 means never expire
+func (c *Client) Touch(key string, expire time.Duration) *pgo.Value {
+    return pgo.NewValue(c.evalKey(scriptTouch, key, milliseconds(expire)))
+}
+
+// GetSet set value of key and return the old value, 0 means never expire
+func (c *Client) GetSet(key string, value interface{}, expire ...time.Duration) *pgo.Value {
+    expire = append(expire, defaultExpire)
+    return pgo.NewValue(c.evalKey(scriptGetSet, key, value, milliseconds(expire[0])))
+}
+
+// CompareAndSwap set value of key if encoded value of key equals
+// to encoded old, false if key not exists, 0 means never expire.
+func (c *Client) CompareAndSwap(key string, old, new interface{}, expire ...time.Duration) bool {
+    expire = append(expire, defaultExpire)
+    num, ok := c.evalKey(scriptCompareAndSwap, key, old, new, milliseconds(expire[0])).(int)
+    return ok && num == 1
+}
+
 func (c *Client) set(key string, value interface{}, expire time.Duration, flag string) bool {
-    newKey := c.BuildKey(key)
-    conn := c.GetConnByKey("SET", newKey)
-    defer conn.Close(false)
+    ok, e := c.setE(key, value, expire, flag)
+    if e != nil {
+        panic(e)
+    }
+
+    return ok
+}
 
-    var res interface{}
+func (c *Client) setE(key string, value interface{}, expire time.Duration, flag string) (bool, error) {
+    var reply interface{}
+    var e error
     if len(flag) == 0 {
-        res = conn.Do("SET", newKey, value, "EX", expire/time.Second)
+        reply, e = c.doKeyE("SET", key, value, "EX", expire/time.Second)
     } else {
-        res = conn.Do("SET", newKey, value, "EX", expire/time.Second, flag)
+        reply, e = c.doKeyE("SET", key, value, "EX", expire/time.Second, flag)
+    }
+
+    payload, ok := reply.([]byte)
+    return ok && bytes.Equal(payload, replyOK), e
+}
+
+// doKeyE send command of key to server of key
+func (c *Client) doKeyE(cmd, key string, args ...interface{}) (interface{}, error) {
+    newKey := c.BuildKey(key)
+    conn, e := c.GetConnByKeyE(cmd, newKey)
+    if e != nil {
+        return nil, e
+    }
+
+    defer conn.Close(false)
+
+    return conn.DoE(cmd, append([]interface{}{newKey}, args...)...)
+}
+
+// evalKey eval lua script of key on server of key
+func (c *Client) evalKey(script, key string, args ...interface{}) interface{} {
+    reply, e := c.evalKeyE(script, key, args...)
+    if e != nil {
+        panic(e)
     }
 
-    payload, ok := res.([]byte)
-    return ok && bytes.Equal(payload, replyOK)
+    return reply
+}
+
+// evalKeyE eval lua script of key, error is returned instead of panic
+func (c *Client) evalKeyE(script, key string, args ...interface{}) (interface{}, error) {
+    newKey := c.BuildKey(key)
+    conn, e := c.GetConnByKeyE("EVAL", newKey)
+    if e != nil {
+        return nil, e
+    }
+
+    defer conn.Close(false)
+
+    return conn.DoE("EVAL", append([]interface{}{script, 1, newKey}, args...)...)
 }
 
 func (c *Client) mset(items map[string]interface{}, expire time.Duration, flag string) bool {
@@ -171,25 +300,39 @@ func (c *Client) mset(items map[string]interface{}, expire time.Duration, flag s
 
 // args = [0:"key"]
 func (c *Client) Do(cmd string, args ...interface{}) interface{} {
+    reply, e := c.DoE(cmd, args...)
+    if e != nil {
+        panic(e)
+    }
+
+    return reply
+}
+
+// DoE send command like Do, error is returned instead of panic
+func (c *Client) DoE(cmd string, args ...interface{}) (interface{}, error) {
     if len(args) == 0 {
-        panic("The length of args has to be greater than 1")
+        return nil, fmt.Errorf("The length of args has to be greater than 1")
     }
 
     key, ok := args[0].(string)
     if ok == false {
-        panic("Invalid key string:" + Util.ToString(args[0]))
+        return nil, fmt.Errorf("Invalid key string:%s", Util.ToString(args[0]))
     }
 
     cmd = strings.ToUpper(cmd)
     if Util.SliceSearchString(allRedisCmd, cmd) == -1 {
-        panic("Undefined command:" + cmd)
+        return nil, fmt.Errorf("Undefined command:%s", cmd)
     }
 
-    newKey := c.BuildKey(key)
-    conn := c.GetConnByKey(cmd, newKey)
-    defer conn.Close(false)
+    return c.doKeyE(cmd, key, args[1:]...)
+}
 
-    args[0] = newKey
+// milliseconds convert expire to milliseconds,
+// expire shorter than 1ms is rounded up.
+func milliseconds(expire time.Duration) int64 {
+    if expire > 0 && expire < time.Millisecond {
+        return 1
+    }
 
-    return conn.Do(cmd, args ...)
+    return int64(expire / time.Millisecond)
 }
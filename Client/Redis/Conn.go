@@ -60,11 +60,34 @@ func (c *Conn) ExtendDeadLine(deadLine ...time.Duration) bool {
 }
 
 func (c *Conn) Do(cmd string, args ...interface{}) interface{} {
-    c.WriteCmd(cmd, args...)
-    return c.ReadReply()
+    reply, e := c.DoE(cmd, args...)
+    if e != nil {
+        panic(e)
+    }
+
+    return reply
+}
+
+// DoE send command and read reply, error is returned instead of
+// panic, kind of error is pgo.ErrTimeout, pgo.ErrNetwork or
+// pgo.ErrProtocol, error reply of server is returned as error
+// without kind, connection is marked down on fatal errors.
+func (c *Conn) DoE(cmd string, args ...interface{}) (interface{}, error) {
+    if e := c.WriteCmdE(cmd, args...); e != nil {
+        return nil, e
+    }
+
+    return c.ReadReplyE()
 }
 
 func (c *Conn) WriteCmd(cmd string, args ...interface{}) {
+    if e := c.WriteCmdE(cmd, args...); e != nil {
+        panic(e)
+    }
+}
+
+// WriteCmdE write command, error is returned instead of panic
+func (c *Conn) WriteCmdE(cmd string, args ...interface{}) error {
     fmt.Fprintf(c.rw, "*%d\r\n$%d\r\n%s\r\n", len(args)+1, len(cmd), cmd)
     for _, arg := range args {
         argBytes := pgo.Encode(arg)
@@ -74,20 +97,32 @@ func (c *Conn) WriteCmd(cmd string, args ...interface{}) {
     }
 
     if e := c.rw.Flush(); e != nil {
-        c.parseError(errSendFailed+e.Error(), true)
+        return c.netError(errSendFailed, e)
     }
+
+    return nil
 }
 
 // read reply from server,
 // return []byte, int, nil or slice of these types
 func (c *Conn) ReadReply() interface{} {
+    reply, e := c.ReadReplyE()
+    if e != nil {
+        panic(e)
+    }
+
+    return reply
+}
+
+// ReadReplyE read reply like ReadReply, error is returned instead of panic
+func (c *Conn) ReadReplyE() (interface{}, error) {
     line, e := c.rw.ReadSlice('\n')
     if e != nil {
-        c.parseError(errReadFailed+e.Error(), true)
+        return nil, c.netError(errReadFailed, e)
     }
 
     if !bytes.HasSuffix(line, lineEnding) {
-        c.parseError(errCorrupted+"unexpected line ending", true)
+        return nil, c.protocolError(errCorrupted + "unexpected line ending")
     }
 
     payload := line[1 : len(line)-2]
@@ -96,63 +131,78 @@ func (c *Conn) ReadReply() interface{} {
     case '+':
         // status response: +<data bytes>\r\n, eg. +OK\r\n, +PONG\r\n
         if bytes.Equal(payload, replyOK) {
-            return replyOK
+            return replyOK, nil
         } else if bytes.Equal(payload, replyPong) {
-            return replyPong
+            return replyPong, nil
         } else {
             data := make([]byte, len(payload))
             copy(data, payload)
-            return data
+            return data, nil
         }
 
     case '-':
         // error response:-<data bytes>\r\n, eg. -Err unknown command\r\n
-        c.parseError(errBase+string(payload), false)
+        return nil, pgo.NewError(nil, nil, "%s%s", errBase, payload)
 
     case ':':
         // integer response: :<integer>\r\n, eg. :99\r\n
         if n, e := strconv.Atoi(string(payload)); e != nil {
-            c.parseError(errCorrupted+e.Error(), true)
+            return nil, c.protocolError(errCorrupted + e.Error())
         } else {
-            return n
+            return n, nil
         }
 
     case '$':
         // bulk string response: $<bytes of data>\r\n<binary data>\r\n,
         // -1 for nil response. eg. $7\r\nfoo bar\r\n
         if size, e := strconv.Atoi(string(payload)); e != nil {
-            c.parseError(errCorrupted+e.Error(), true)
+            return nil, c.protocolError(errCorrupted + e.Error())
         } else if size >= 0 {
             data := make([]byte, size+2)
             if _, e := io.ReadFull(c.rw, data); e != nil {
-                c.parseError(errCorrupted+e.Error(), true)
+                return nil, c.netError(errCorrupted, e)
             }
-            return data[:size]
+            return data[:size], nil
         }
 
     case '*':
         // multi response: *<argc>\r\n$<bytes of arg1>\r\n<data of arg1>\r\n[argN...],
         // -1 for nil response. eg. *2\r\n$3\r\nfoo\r\n$3\r\nbar\r\n
         if argc, e := strconv.Atoi(string(payload)); e != nil {
-            c.parseError(errCorrupted+e.Error(), true)
+            return nil, c.protocolError(errCorrupted + e.Error())
         } else if argc >= 0 {
-            argv := make([]interface{}, argc)
+            // read all elements to keep connection usable on error reply
+            argv, err := make([]interface{}, argc), error(nil)
             for i := range argv {
-                argv[i] = c.ReadReply()
+                if argv[i], e = c.ReadReplyE(); e != nil {
+                    if c.down {
+                        return nil, e
+                    } else if err == nil {
+                        err = e
+                    }
+                }
             }
-            return argv
+
+            if err != nil {
+                return nil, err
+            }
+            return argv, nil
         }
 
     default:
-        c.parseError(errInvalidResp+string(line[:1]), true)
+        return nil, c.protocolError(errInvalidResp + string(line[:1]))
     }
-    return nil
+    return nil, nil
 }
 
-func (c *Conn) parseError(err string, fatal bool) {
-    if fatal {
-        c.down = true
-    }
+// netError mark connection down and create network error
+func (c *Conn) netError(msg string, e error) error {
+    c.down = true
+    return pgo.NewNetError(e, "%s%s", msg, e.Error())
+}
 
-    panic(err)
+// protocolError mark connection down and create protocol error
+func (c *Conn) protocolError(msg string) error {
+    c.down = true
+    return pgo.NewError(pgo.ErrProtocol, nil, "%s", msg)
 }
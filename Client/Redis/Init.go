@@ -41,6 +41,63 @@ const (
     NodeActionDel = "del"
 )
 
+// lua scripts of atomic operations, expire is in milliseconds,
+// 0 means never expire.
+const (
+    scriptExpire = `
+if redis.call('EXISTS', KEYS[1]) == 0 then return 0 end
+if tonumber(ARGV[1]) > 0 then
+    redis.call('PEXPIRE', KEYS[1], ARGV[1])
+else
+    redis.call('PERSIST', KEYS[1])
+end
+return 1`
+
+    scriptTouch = `
+local v = redis.call('GET', KEYS[1])
+if not v then return v end
+if tonumber(ARGV[1]) > 0 then
+    redis.call('PEXPIRE', KEYS[1], ARGV[1])
+else
+    redis.call('PERSIST', KEYS[1])
+end
+return v`
+
+    scriptGetSet = `
+local v = redis.call('GET', KEYS[1])
+if tonumber(ARGV[2]) > 0 then
+    redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+else
+    redis.call('SET', KEYS[1], ARGV[1])
+end
+return v`
+
+    scriptCompareAndSwap = `
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then return 0 end
+if tonumber(ARGV[3]) > 0 then
+    redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+    redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1`
+
+    scriptIncrWithExpire = `
+local created = redis.call('EXISTS', KEYS[1]) == 0
+local n = redis.call('INCRBY', KEYS[1], ARGV[1])
+if created and tonumber(ARGV[2]) > 0 then
+    redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return n`
+
+    scriptRenewLock = `
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then return 0 end
+return redis.call('PEXPIRE', KEYS[1], ARGV[2])`
+
+    scriptReleaseLock = `
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then return 0 end
+return redis.call('DEL', KEYS[1])`
+)
+
 var (
     lineEnding = []byte("\r\n")
     replyOK    = []byte("OK")
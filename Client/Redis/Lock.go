@@ -0,0 +1,37 @@
+package Redis
+
+import (
+    "bytes"
+    "time"
+
+    "github.com/pinguo/pgo"
+)
+
+// NewLock create distributed lock of key, ttl is expire of lock,
+// lock is stored as key with token as value, see pgo.Lock.
+func (c *Client) NewLock(key string, ttl time.Duration) *pgo.Lock {
+    return pgo.NewLock(c, key, ttl)
+}
+
+// AcquireLock set key to token if key not exists, implement pgo.ILockStore
+func (c *Client) AcquireLock(key, token string, ttl time.Duration) bool {
+    reply, e := c.doKeyE("SET", key, token, "PX", milliseconds(ttl), "NX")
+    if e != nil {
+        panic(e)
+    }
+
+    payload, ok := reply.([]byte)
+    return ok && bytes.Equal(payload, replyOK)
+}
+
+// RenewLock reset expire of key if value of key is token, implement pgo.ILockStore
+func (c *Client) RenewLock(key, token string, ttl time.Duration) bool {
+    num, ok := c.evalKey(scriptRenewLock, key, token, milliseconds(ttl)).(int)
+    return ok && num == 1
+}
+
+// ReleaseLock delete key if value of key is token, implement pgo.ILockStore
+func (c *Client) ReleaseLock(key, token string) bool {
+    num, ok := c.evalKey(scriptReleaseLock, key, token).(int)
+    return ok && num == 1
+}
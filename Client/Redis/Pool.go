@@ -38,11 +38,15 @@ type Pool struct {
     mod           string
     modObj        IPool
 
+    stop     chan struct{}
+    stopOnce sync.Once
+
     // 重新检查标志
     reCheck string
 }
 
 func (p *Pool) Construct() {
+    p.stop = make(chan struct{})
     p.hashRing = Util.NewHashRing()
     p.connLists = make(map[string]*connList)
     p.servers = make(map[string]*serverInfo)
@@ -82,6 +86,24 @@ func (p *Pool) Init() {
     }
 }
 
+// Stop stop probe loop and close idle connections
+func (p *Pool) Stop() error {
+    p.stopOnce.Do(func() { close(p.stop) })
+
+    p.lock.Lock()
+    defer p.lock.Unlock()
+
+    for addr, list := range p.connLists {
+        for conn := list.head; conn != nil; conn = conn.next {
+            conn.nc.Close()
+        }
+
+        delete(p.connLists, addr)
+    }
+
+    return nil
+}
+
 func (p *Pool) SetPrefix(prefix string) {
     p.prefix = prefix
 }
@@ -200,21 +222,45 @@ func (p *Pool) RunAddrFunc(addr string, keys []string, wg *sync.WaitGroup, f fun
 }
 
 func (p *Pool) GetConnByKey(cmd, key string) *Conn {
+    conn, e := p.GetConnByKeyE(cmd, key)
+    if e != nil {
+        panic(e)
+    }
+
+    return conn
+}
+
+// GetConnByKeyE get connection of key, error is returned instead
+// of panic, error is pgo.ErrNoServer if no server available.
+func (p *Pool) GetConnByKeyE(cmd, key string) (*Conn, error) {
     if addr := p.GetAddrByKey(cmd, key); len(addr) == 0 {
-        panic(errNoServer)
+        return nil, pgo.NewError(pgo.ErrNoServer, nil, errNoServer)
     } else {
-        return p.GetConnByAddr(addr)
+        return p.GetConnByAddrE(addr)
     }
 }
 
 func (p *Pool) GetConnByAddr(addr string) *Conn {
+    conn, e := p.GetConnByAddrE(addr)
+    if e != nil {
+        panic(e)
+    }
+
+    return conn
+}
+
+// GetConnByAddrE get connection of addr, error is returned instead of panic
+func (p *Pool) GetConnByAddrE(addr string) (*Conn, error) {
     conn := p.getFreeConn(addr)
     if conn == nil || !p.checkConn(conn) {
-        conn = p.dial(addr)
+        var e error
+        if conn, e = p.dial(addr); e != nil {
+            return nil, e
+        }
     }
 
     conn.ExtendDeadLine()
-    return conn
+    return conn, nil
 }
 
 // get redis address/node
@@ -288,28 +334,28 @@ func (p *Pool) checkConn(conn *Conn) bool {
     return true
 }
 
-func (p *Pool) dial(addr string) *Conn {
-    if nc, e := net.DialTimeout(p.parseNetwork(addr), addr, p.netTimeout); e != nil {
-        panic(errBase + e.Error())
-    } else {
-        conn := newConn(addr, nc, p)
-        defer func() {
-            if v := recover(); v != nil {
-                conn.Close(true)
-                panic(v)
-            }
-        }()
-
-        if len(p.password) > 0 {
-            conn.Do("AUTH", p.password)
-        }
+func (p *Pool) dial(addr string) (*Conn, error) {
+    nc, e := net.DialTimeout(p.parseNetwork(addr), addr, p.netTimeout)
+    if e != nil {
+        return nil, pgo.NewNetError(e, "%s%s", errBase, e.Error())
+    }
 
-        if p.db > 0 {
-            conn.Do("SELECT", p.db)
+    conn := newConn(addr, nc, p)
+    if len(p.password) > 0 {
+        if _, e := conn.DoE("AUTH", p.password); e != nil {
+            conn.Close(true)
+            return nil, e
         }
+    }
 
-        return conn
+    if p.db > 0 {
+        if _, e := conn.DoE("SELECT", p.db); e != nil {
+            conn.Close(true)
+            return nil, e
+        }
     }
+
+    return conn, nil
 }
 
 func (p *Pool) parseNetwork(addr string) string {
@@ -325,10 +371,11 @@ func (p *Pool) probeServer(addr string) {
     if e != nil && !p.servers[addr].disabled {
         p.setServerDisabled(addr, true)
         p.modObj.check(addr, NodeActionDel)
+        pgo.App.GetEventBus().Trigger(pgo.EventPoolDown, nil, "redis", addr)
     } else if e == nil && p.servers[addr].disabled {
         p.setServerDisabled(addr, false)
         p.modObj.check(addr, NodeActionAdd)
-
+        pgo.App.GetEventBus().Trigger(pgo.EventPoolUp, nil, "redis", addr)
     }
 
     if e == nil {
@@ -352,7 +399,12 @@ func (p *Pool) setServerDisabled(addr string, disabled bool) {
 
 func (p *Pool) probeLoop() {
     for {
-        <-time.After(p.probeInterval)
+        select {
+        case <-time.After(p.probeInterval):
+        case <-p.stop:
+            return
+        }
+
         for addr := range p.servers {
             p.probeServer(addr)
         }
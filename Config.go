@@ -1,6 +1,7 @@
 package pgo
 
 import (
+    "context"
     "encoding/json"
     "fmt"
     "io/ioutil"
@@ -8,22 +9,80 @@ import (
     "path/filepath"
     "strings"
     "sync"
+    "time"
 
     "github.com/pinguo/pgo/Util"
 )
 
-// Config the config component
+// Config the config component, config of a top-level key is merged
+// in order of precedence from low to high:
+// 1. files under conf path
+// 2. files under conf/<env> path
+// 3. remote providers in order of adding
+// 4. environment variables like PGO_APP__SERVER__HTTPADDR
+// 5. overlays added by AddOverlay, eg. --set app.server.httpAddr=:9000
+// 6. values set by Set
+// json, yaml, toml and ini files are supported, .env files under
+// base path, conf path and env path are loaded into environment
+// before parsing, existing environment variables take precedence.
+// a file can extend other files by top-level key "$extends", and
+// include a file as value of a key by "!include path", paths are
+// relative to the file, eg. app.yaml:
+// $extends: base.yaml
+// components:
+//     db: !include db.yaml
+// Explain shows the sources which provide value of a key.
+// secret references are resolved in files, providers and overlays:
+// ${file:/run/secrets/db_pass} content of file, trailing newline trimmed
+// enc:base64(nonce+ciphertext) AES-GCM encrypted value, the key is
+// base64 encoded in env PgoSecretKey or file of env PgoSecretKeyFile.
+// resolved secrets are masked in log messages and Dump.
+// config files are checked every watch interval if watching is started,
+// changed top-level key is re-parsed and swapped atomically, and
+// subscribers of changed keys will be notified.
 type Config struct {
     parsers map[string]IConfigParser
     data    map[string]interface{}
     paths   []string
     lock    sync.RWMutex
+
+    secrets   configSecrets
+    files     map[string][]configFile // files loaded for each top-level key
+    sets      []configSet             // values set by Set, kept on reload
+    providers []*configProvider
+    overlays  []configOverlay         // values from env and flags
+    watchers  []configWatcher
+    watchCtx  context.Context
+    watchStop context.CancelFunc
+    stopWg    sync.WaitGroup
 }
 
-func (c *Config) Construct() {
+// configFile state of a loaded config file
+type configFile struct {
+    path    string
+    from    string // file which extends or includes this file
+    modTime time.Time
+    size    int64
+    data    map[string]interface{}
+}
+
+// configLayer value of a key provided by a config source
+type configLayer struct {
+    source string
+    value  interface{}
+}
+
+type configSet struct {
+    key string
+    val interface{}
+}
+
+// Construct create config for running env, or env specified
+func (c *Config) Construct(env ...string) {
     c.parsers = make(map[string]IConfigParser)
     c.data = make(map[string]interface{})
     c.paths = make([]string, 0)
+    c.files = make(map[string][]configFile)
 
     confPath := filepath.Join(App.GetBasePath(), "conf")
     if f, _ := os.Stat(confPath); f != nil && f.IsDir() {
@@ -32,15 +91,56 @@ func (c *Config) Construct() {
         panic("Config: invalid conf path, " + confPath)
     }
 
-    envPath := filepath.Join(confPath, App.GetEnv())
+    curEnv := App.GetEnv()
+    if len(env) > 0 {
+        curEnv = env[0]
+    }
+
+    envPath := filepath.Join(confPath, curEnv)
     if f, _ := os.Stat(envPath); f != nil && f.IsDir() {
         c.paths = append(c.paths, envPath)
-    } else if App.GetEnv() != DefaultEnv {
+    } else if curEnv != DefaultEnv {
         panic("Config: invalid env path, " + envPath)
     }
 
+    c.loadDotEnv()
+    c.overlays = envOverlays()
+
     c.AddParser("json", &JsonConfigParser{})
     c.AddParser("yaml", &YamlConfigParser{})
+    c.AddParser("toml", &TomlConfigParser{})
+    c.AddParser("ini", &IniConfigParser{})
+}
+
+// loadDotEnv load .env files under base path and search paths
+// into process environment, later file overrides earlier one,
+// variables already exist in environment are not overridden.
+func (c *Config) loadDotEnv() {
+    paths := append([]string{App.GetBasePath()}, c.paths...)
+    vars := make(map[string]string)
+
+    for _, path := range paths {
+        file := filepath.Join(path, ".env")
+        content, e := ioutil.ReadFile(file)
+        if e != nil {
+            continue
+        }
+
+        fileVars, e := Util.ParseDotEnv(content)
+        if e != nil {
+            panic(fmt.Sprintf("Config: failed to parse file: %s, %s", file, e.Error()))
+        }
+
+        for k, v := range fileVars {
+            vars[k] = v
+        }
+    }
+
+    for k, v := range vars {
+        if _, ok := os.LookupEnv(k); !ok {
+            os.Setenv(k, v)
+        }
+    }
 }
 
 // AddParser add parser for file with ext extension
@@ -174,7 +274,11 @@ func (c *Config) GetSliceString(key string) []string {
 // all loaded config will be returned.
 func (c *Config) Get(key string) interface{} {
     ks := strings.Split(key, ".")
-    if _, ok := c.data[ks[0]]; !ok {
+    c.lock.RLock()
+    _, ok := c.data[ks[0]]
+    c.lock.RUnlock()
+
+    if !ok {
         c.Load(ks[0])
     }
 
@@ -187,12 +291,23 @@ func (c *Config) Get(key string) interface{} {
 // Set set value by dot separated key,
 // if key is empty, the value will set
 // to root, if val is nil, the key will
-// be deleted.
+// be deleted. the value is kept when config
+// file is reloaded.
 func (c *Config) Set(key string, val interface{}) {
     c.lock.Lock()
     defer c.lock.Unlock()
 
     Util.MapSet(c.data, key, val)
+
+    // record value to replay after reload
+    sets := make([]configSet, 0, len(c.sets)+1)
+    for _, v := range c.sets {
+        if v.key != key {
+            sets = append(sets, v)
+        }
+    }
+
+    c.sets = append(sets, configSet{key, val})
 }
 
 // Load load config file under the search paths.
@@ -207,17 +322,120 @@ func (c *Config) Load(name string) {
         return
     }
 
+    data := make(map[string]interface{})
+    c.files[name] = c.parse(name, data)
+    c.overlay(name, data)
+    Util.MapMerge(c.data, data)
+}
+
+// overlay merge remote config and values set by Set into data
+func (c *Config) overlay(name string, data map[string]interface{}) {
+    for _, p := range c.providers {
+        if conf, ok := p.data[name]; ok {
+            conf = c.resolveSecrets(fmt.Sprintf("remote:%T", p.provider), name, copyConfig(conf))
+            Util.MapMerge(data, map[string]interface{}{name: conf})
+        }
+    }
+
+    for _, v := range c.overlays {
+        if path, val, ok := v.resolve(name, data); ok {
+            Util.MapSet(data, path, c.resolveSecrets(v.source, path, val))
+        }
+    }
+
+    for _, v := range c.sets {
+        if v.key == name || strings.HasPrefix(v.key, name+".") {
+            Util.MapSet(data, v.key, v.val)
+        }
+    }
+}
+
+// parse config files of name under the search paths,
+// the parsed config is merged into data, files parsed
+// are returned, including files extended or included.
+func (c *Config) parse(name string, data map[string]interface{}) []configFile {
+    parsed := make([]configFile, 0)
     for _, path := range c.paths {
         files, _ := filepath.Glob(filepath.Join(path, name+".*"))
         for _, f := range files {
             ext := strings.ToLower(filepath.Ext(f))
-            if parser, ok := c.parsers[ext[1:]]; ok {
-                if conf := parser.Parse(f); conf != nil {
-                    Util.MapMerge(c.data, map[string]interface{}{name: conf})
+            if _, ok := c.parsers[ext[1:]]; ok {
+                if _, e := os.Stat(f); e != nil {
+                    continue
+                }
+
+                for _, file := range c.parseFile(name, name, f, "", nil) {
+                    parsed = append(parsed, file)
+
+                    if conf, ok := copyConfig(file.data).(map[string]interface{}); ok && conf != nil {
+                        c.resolveSecrets(file.path, name, conf)
+                        Util.MapMerge(data, map[string]interface{}{name: conf})
+                    }
                 }
             }
         }
     }
+
+    return parsed
+}
+
+// layers get values of dot separated key provided by each
+// config source, in order of precedence from low to high.
+func (c *Config) layers(key string) []configLayer {
+    name := strings.Split(key, ".")[0]
+    c.Get(name)
+
+    c.lock.RLock()
+    defer c.lock.RUnlock()
+
+    layers := make([]configLayer, 0)
+    for _, f := range c.files[name] {
+        if v := Util.MapGet(map[string]interface{}{name: f.data}, key); v != nil {
+            layers = append(layers, configLayer{f.path, v})
+        }
+    }
+
+    for _, p := range c.providers {
+        if v := Util.MapGet(p.data, key); v != nil {
+            layers = append(layers, configLayer{fmt.Sprintf("remote:%T", p.provider), v})
+        }
+    }
+
+    for _, v := range c.overlays {
+        if val := v.get(key); val != nil {
+            layers = append(layers, configLayer{v.source, val})
+        }
+    }
+
+    for _, v := range c.sets {
+        if v.val == nil {
+            continue
+        } else if v.key == key {
+            layers = append(layers, configLayer{"set:" + v.key, v.val})
+        } else if strings.HasPrefix(key, v.key+".") {
+            if m, ok := v.val.(map[string]interface{}); ok {
+                if val := Util.MapGet(m, strings.TrimPrefix(key, v.key+".")); val != nil {
+                    layers = append(layers, configLayer{"set:" + v.key, val})
+                }
+            }
+        } else if strings.HasPrefix(v.key, key+".") {
+            layers = append(layers, configLayer{"set:" + v.key, v.val})
+        }
+    }
+
+    return layers
+}
+
+// source get the source which provides value of key or its
+// nearest parent key, empty string returned if not found.
+func (c *Config) source(key string) string {
+    for ks := strings.Split(key, "."); len(ks) > 0; ks = ks[:len(ks)-1] {
+        if layers := c.layers(strings.Join(ks, ".")); len(layers) > 0 {
+            return layers[len(layers)-1].source
+        }
+    }
+
+    return ""
 }
 
 // JsonConfigParser parser for json config
@@ -226,16 +444,26 @@ type JsonConfigParser struct {
 
 // Parse parse json config, environment value like ${env||default} will expand
 func (j *JsonConfigParser) Parse(path string) map[string]interface{} {
+    data, e := j.ParseE(path)
+    if e != nil {
+        panic(e.Error())
+    }
+
+    return data
+}
+
+// ParseE parse json config like Parse, error is returned instead of panic
+func (j *JsonConfigParser) ParseE(path string) (map[string]interface{}, error) {
     h, e := os.Open(path)
     if e != nil {
-        panic("JsonConfigParser: failed to open file: " + path)
+        return nil, fmt.Errorf("JsonConfigParser: failed to open file: %s, %w", path, e)
     }
 
     defer h.Close()
 
     content, e := ioutil.ReadAll(h)
     if e != nil {
-        panic("JsonConfigParser: failed to read file: " + path)
+        return nil, fmt.Errorf("JsonConfigParser: failed to read file: %s, %w", path, e)
     }
 
     // expand env: ${env||default}
@@ -243,10 +471,10 @@ func (j *JsonConfigParser) Parse(path string) map[string]interface{} {
 
     var data map[string]interface{}
     if e := json.Unmarshal(content, &data); e != nil {
-        panic(fmt.Sprintf("jsonConfigParser: failed to parse file: %s, %s", path, e.Error()))
+        return nil, fmt.Errorf("JsonConfigParser: failed to parse file: %s, %w", path, e)
     }
 
-    return data
+    return data, nil
 }
 
 // YamlConfigParser parser for yaml config
@@ -255,25 +483,116 @@ type YamlConfigParser struct {
 
 // Parse parse yaml config, environment value like ${env||default} will expand
 func (y *YamlConfigParser) Parse(path string) map[string]interface{} {
+    data, e := y.ParseE(path)
+    if e != nil {
+        panic(e.Error())
+    }
+
+    return data
+}
+
+// ParseE parse yaml config like Parse, error is returned instead of panic
+func (y *YamlConfigParser) ParseE(path string) (map[string]interface{}, error) {
     h, e := os.Open(path)
     if e != nil {
-        panic("YamlConfigParser: failed to open file: " + path)
+        return nil, fmt.Errorf("YamlConfigParser: failed to open file: %s, %w", path, e)
     }
 
     defer h.Close()
 
     content, e := ioutil.ReadAll(h)
     if e != nil {
-        panic("YamlConfigParser: failed to read file: " + path)
+        return nil, fmt.Errorf("YamlConfigParser: failed to read file: %s, %w", path, e)
     }
 
     // expand env: ${env||default}
     content = Util.ExpandEnv(content)
 
+    // keep include tag as string: !include path => "!include path"
+    content = yamlIncludeRe.ReplaceAll(content, []byte(`${1}"!include ${2}"`))
+
     var data map[string]interface{}
     if e := Util.YamlUnmarshal(content, &data); e != nil {
-        panic(fmt.Sprintf("YamlConfigParser: failed to parse file: %s, %s", path, e.Error()))
+        return nil, fmt.Errorf("YamlConfigParser: failed to parse file: %s, %w", path, e)
+    }
+
+    return data, nil
+}
+
+// TomlConfigParser parser for toml config
+type TomlConfigParser struct {
+}
+
+// Parse parse toml config, environment value like ${env||default} will expand
+func (t *TomlConfigParser) Parse(path string) map[string]interface{} {
+    data, e := t.ParseE(path)
+    if e != nil {
+        panic(e.Error())
     }
 
     return data
 }
+
+// ParseE parse toml config like Parse, error is returned instead of panic
+func (t *TomlConfigParser) ParseE(path string) (map[string]interface{}, error) {
+    h, e := os.Open(path)
+    if e != nil {
+        return nil, fmt.Errorf("TomlConfigParser: failed to open file: %s, %w", path, e)
+    }
+
+    defer h.Close()
+
+    content, e := ioutil.ReadAll(h)
+    if e != nil {
+        return nil, fmt.Errorf("TomlConfigParser: failed to read file: %s, %w", path, e)
+    }
+
+    // expand env: ${env||default}
+    content = Util.ExpandEnv(content)
+
+    data, e := Util.TomlUnmarshal(content)
+    if e != nil {
+        return nil, fmt.Errorf("TomlConfigParser: failed to parse file: %s, %w", path, e)
+    }
+
+    return data, nil
+}
+
+// IniConfigParser parser for ini config
+type IniConfigParser struct {
+}
+
+// Parse parse ini config, environment value like ${env||default} will expand
+func (i *IniConfigParser) Parse(path string) map[string]interface{} {
+    data, e := i.ParseE(path)
+    if e != nil {
+        panic(e.Error())
+    }
+
+    return data
+}
+
+// ParseE parse ini config like Parse, error is returned instead of panic
+func (i *IniConfigParser) ParseE(path string) (map[string]interface{}, error) {
+    h, e := os.Open(path)
+    if e != nil {
+        return nil, fmt.Errorf("IniConfigParser: failed to open file: %s, %w", path, e)
+    }
+
+    defer h.Close()
+
+    content, e := ioutil.ReadAll(h)
+    if e != nil {
+        return nil, fmt.Errorf("IniConfigParser: failed to read file: %s, %w", path, e)
+    }
+
+    // expand env: ${env||default}
+    content = Util.ExpandEnv(content)
+
+    data, e := Util.IniUnmarshal(content)
+    if e != nil {
+        return nil, fmt.Errorf("IniConfigParser: failed to parse file: %s, %w", path, e)
+    }
+
+    return data, nil
+}
@@ -0,0 +1,156 @@
+package pgo
+
+import (
+    "fmt"
+    "io/ioutil"
+    "path/filepath"
+    "reflect"
+    "sort"
+    "strings"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+// CheckConfig check config of container, server and components
+// for every env under conf path, objects are not created, config
+// keys are checked against setters and public fields of class,
+// unknown keys and type errors are returned.
+func (app *Application) CheckConfig() []error {
+    envs := []string{DefaultEnv}
+    dirs, _ := ioutil.ReadDir(filepath.Join(app.basePath, "conf"))
+    for _, d := range dirs {
+        if d.IsDir() && d.Name() != DefaultEnv {
+            envs = append(envs, d.Name())
+        }
+    }
+
+    errs := make([]error, 0)
+    for _, env := range envs {
+        errs = append(errs, app.checkEnv(env)...)
+    }
+
+    return errs
+}
+
+// checkEnv check config of env
+func (app *Application) checkEnv(env string) (errs []error) {
+    defer func() {
+        if v := recover(); v != nil {
+            errs = append(errs, fmt.Errorf("env:%s, %s", env, Util.ToString(v)))
+        }
+    }()
+
+    config := &Config{}
+    config.Construct(env)
+
+    checker := configChecker{env: env, config: config}
+    checker.check("app.container", reflect.ValueOf(&Container{}))
+    checker.check("app.server", reflect.ValueOf(&Server{}))
+
+    // check configured and core components
+    components, _ := config.Get("app.components").(map[string]interface{})
+    ids := make(map[string]string)
+    for id := range components {
+        ids[id] = ""
+    }
+
+    for id, class := range app.coreComponents() {
+        // skip core component not bound and not configured
+        if _, ok := components[id]; ok || App.GetContainer().Has(GetAlias(class)) {
+            ids[id] = class
+        }
+    }
+
+    for _, id := range sortedKeys(ids) {
+        key := "app.components." + id
+        class := config.GetString(key+".class", ids[id])
+        if len(class) == 0 {
+            checker.error(key, fmt.Errorf("class is required"))
+            continue
+        }
+
+        checker.checkClass(key, class)
+    }
+
+    return checker.errs
+}
+
+// configChecker check config keys and types of objects
+type configChecker struct {
+    env    string
+    config *Config
+    errs   []error
+}
+
+func (c *configChecker) error(key string, err error) {
+    e := &ConfigError{File: c.config.source(key), Key: key, Err: err}
+    c.errs = append(c.errs, fmt.Errorf("env:%s, %s", c.env, e))
+}
+
+// checkClass check config of key against class
+func (c *configChecker) checkClass(key, class string) {
+    name := GetAlias(class)
+    if len(name) == 0 || !App.GetContainer().Has(name) {
+        c.error(key+".class", fmt.Errorf("unknown class: %s", class))
+        return
+    }
+
+    c.check(key, reflect.New(App.GetContainer().GetType(name)))
+}
+
+// check check config of key against object v
+func (c *configChecker) check(key string, v reflect.Value) {
+    config, _ := c.config.Get(key).(map[string]interface{})
+
+    for _, k := range sortedKeys(config) {
+        if k == "class" || k == componentDependsKey && strings.Count(key, ".") == 2 && strings.HasPrefix(key, "app.components.") {
+            continue
+        }
+
+        val := config[k]
+        subKey := key + "." + k
+
+        var t reflect.Type
+        if method, field := configTarget(v, k); method.IsValid() {
+            t = method.Type().In(0)
+        } else if field.IsValid() {
+            t = field.Type()
+        } else {
+            c.error(subKey, fmt.Errorf("unknown key"))
+            continue
+        }
+
+        if e := checkConfigType(val, t); e != nil {
+            c.error(subKey, e)
+            continue
+        }
+
+        // check nested object and map of objects
+        if m, ok := val.(map[string]interface{}); ok {
+            if class, ok := m["class"].(string); ok {
+                c.checkClass(subKey, class)
+                continue
+            }
+
+            for _, mk := range sortedKeys(m) {
+                if mm, ok := m[mk].(map[string]interface{}); ok {
+                    if class, ok := mm["class"].(string); ok {
+                        c.checkClass(subKey+"."+mk, class)
+                    }
+                }
+            }
+        }
+    }
+}
+
+// sortedKeys get sorted keys of map, m must be a map with string key
+func sortedKeys(m interface{}) []string {
+    rv := reflect.ValueOf(m)
+    keys := make([]string, 0, rv.Len())
+    for _, k := range rv.MapKeys() {
+        keys = append(keys, k.String())
+    }
+
+    sort.Strings(keys)
+    return keys
+}
@@ -0,0 +1,161 @@
+package pgo
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+const (
+    configExtendsKey    = "$extends"
+    configIncludePrefix = "!include "
+)
+
+// unquoted include tag of yaml value
+var yamlIncludeRe = regexp.MustCompile(`(?m)((?:^|:|-)[ \t]+)!include[ \t]+([^\s#"']+)`)
+
+// parseFile parse config file and files it extends or includes,
+// files are returned in order of precedence from low to high,
+// key is the dot separated key where data of file is located,
+// data of each returned file is relative to top-level key name.
+func (c *Config) parseFile(name, key, path, from string, stack []string) []configFile {
+    for _, v := range stack {
+        if v == path {
+            panic(fmt.Sprintf("Config: circular include, %s", strings.Join(append(stack, path), " -> ")))
+        }
+    }
+
+    stack = append(stack, path)
+
+    ext := strings.ToLower(filepath.Ext(path))
+    parser, ok := c.parsers[strings.TrimPrefix(ext, ".")]
+    if !ok {
+        panic(fmt.Sprintf("Config: unsupported file: %s, included by %s", path, from))
+    }
+
+    info, e := os.Stat(path)
+    if e != nil {
+        panic(fmt.Sprintf("Config: failed to stat file: %s, included by %s", path, from))
+    }
+
+    conf := parser.Parse(path)
+    files := make([]configFile, 0)
+
+    // files extended have lower precedence
+    if v, ok := conf[configExtendsKey]; ok {
+        delete(conf, configExtendsKey)
+
+        var bases []interface{}
+        switch val := v.(type) {
+        case string:
+            bases = []interface{}{val}
+        case []interface{}:
+            bases = val
+        default:
+            panic(fmt.Sprintf("Config: invalid %s in file: %s", configExtendsKey, path))
+        }
+
+        for _, base := range bases {
+            files = append(files, c.parseFile(name, key, c.includePath(path, Util.ToString(base)), path, stack)...)
+        }
+    }
+
+    files = append(files, c.include(name, key, conf, path, stack)...)
+
+    // locate data of file under top-level key
+    var data map[string]interface{}
+    if key == name {
+        data = conf
+    } else if conf != nil {
+        data = make(map[string]interface{})
+        Util.MapSet(data, strings.TrimPrefix(key, name+"."), conf)
+    }
+
+    return append(files, configFile{path, from, info.ModTime(), info.Size(), data})
+}
+
+// include parse files included by values of m, the include
+// values are removed from m, files included are returned.
+func (c *Config) include(name, key string, m map[string]interface{}, path string, stack []string) []configFile {
+    files := make([]configFile, 0)
+    for _, k := range sortedKeys(m) {
+        switch val := m[k].(type) {
+        case string:
+            if strings.HasPrefix(val, configIncludePrefix) {
+                delete(m, k)
+                file := c.includePath(path, strings.TrimSpace(val[len(configIncludePrefix):]))
+                files = append(files, c.parseFile(name, key+"."+k, file, path, stack)...)
+            }
+        case map[string]interface{}:
+            files = append(files, c.include(name, key+"."+k, val, path, stack)...)
+        }
+    }
+
+    return files
+}
+
+// includePath get path of file extended or included by from,
+// relative path is relative to directory of from.
+func (c *Config) includePath(from, path string) string {
+    if strings.HasPrefix(path, "@") {
+        path = GetAlias(path)
+    }
+
+    if !filepath.IsAbs(path) {
+        path = filepath.Join(filepath.Dir(from), path)
+    }
+
+    return filepath.Clean(path)
+}
+
+// Explain explain how value of dot separated key is produced,
+// every leaf key under key is listed with its final value and
+// values provided by each config source in order of precedence
+// from low to high, secrets are masked, eg.
+// app.server.httpAddr = ":9000"
+//     conf/app.yaml: ":8000"
+//     conf/online/app.yaml: ":8080"
+//     env:PGO_APP__SERVER__HTTPADDR: ":9000"
+func (c *Config) Explain(key string) string {
+    var b strings.Builder
+    c.explain(&b, key, c.Get(key))
+    return b.String()
+}
+
+func (c *Config) explain(b *strings.Builder, key string, val interface{}) {
+    if m, ok := val.(map[string]interface{}); ok && len(m) > 0 {
+        for _, k := range sortedKeys(m) {
+            c.explain(b, key+"."+k, m[k])
+        }
+        return
+    }
+
+    fmt.Fprintf(b, "%s = %s\n", key, c.explainValue(val))
+    for _, layer := range c.layers(key) {
+        source := layer.source
+        if rel, e := filepath.Rel(App.GetBasePath(), source); e == nil && !strings.HasPrefix(rel, "..") {
+            source = rel
+        }
+
+        fmt.Fprintf(b, "    %s: %s\n", source, c.explainValue(layer.value))
+    }
+}
+
+// explainValue format value as json with secrets masked
+func (c *Config) explainValue(val interface{}) string {
+    if val == nil {
+        return "<not set>"
+    }
+
+    content, e := json.Marshal(c.maskValue(copyConfig(val)))
+    if e != nil {
+        return c.Mask(fmt.Sprint(val))
+    }
+
+    return string(content)
+}
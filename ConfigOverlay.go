@@ -0,0 +1,118 @@
+package pgo
+
+import (
+    "fmt"
+    "os"
+    "sort"
+    "strings"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+// EnvOverlayPrefix prefix of environment variables to override config,
+// "__" separates key segments, eg. PGO_APP__SERVER__HTTPADDR
+const EnvOverlayPrefix = "PGO_"
+
+// configOverlay value overrides dot separated key after files
+// and providers are merged, key segments are matched to existing
+// keys case-insensitively and regardless of underscores.
+type configOverlay struct {
+    key    string
+    val    string
+    source string
+}
+
+// envOverlays get overlays from environment variables
+func envOverlays() []configOverlay {
+    overlays := make([]configOverlay, 0)
+    for _, kv := range os.Environ() {
+        pos := strings.IndexByte(kv, '=')
+        if pos <= len(EnvOverlayPrefix) || !strings.HasPrefix(kv, EnvOverlayPrefix) {
+            continue
+        }
+
+        name := kv[:pos]
+        key := strings.Replace(strings.ToLower(name[len(EnvOverlayPrefix):]), "__", ".", -1)
+        overlays = append(overlays, configOverlay{key, kv[pos+1:], "env:" + name})
+    }
+
+    // make the order stable
+    sort.Slice(overlays, func(i, j int) bool { return overlays[i].key < overlays[j].key })
+    return overlays
+}
+
+// AddOverlay add value to override dot separated key, value is
+// decoded as yaml scalar or flow unless existing value is string.
+func (c *Config) AddOverlay(key, val, source string) {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    c.overlays = append(c.overlays, configOverlay{key, val, source})
+
+    // drop loaded key to apply overlay on next loading
+    delete(c.data, strings.Split(key, ".")[0])
+}
+
+// resolve get key path and value of overlay if top-level key matches name
+func (o *configOverlay) resolve(name string, data map[string]interface{}) (string, interface{}, bool) {
+    ks := strings.Split(o.key, ".")
+    if normalizeKey(ks[0]) != normalizeKey(name) {
+        return "", nil, false
+    }
+
+    // resolve key segments to existing keys
+    path, node := name, data[name]
+    for _, k := range ks[1:] {
+        m, _ := node.(map[string]interface{})
+        node = nil
+        for mk, mv := range m {
+            if normalizeKey(mk) == normalizeKey(k) {
+                k, node = mk, mv
+                break
+            }
+        }
+
+        path += "." + k
+    }
+
+    var val interface{} = o.val
+    if _, ok := node.(string); !ok {
+        var m map[string]interface{}
+        if e := Util.YamlUnmarshal([]byte("v: "+o.val), &m); e == nil && m["v"] != nil {
+            val = m["v"]
+        }
+    }
+
+    return path, val, true
+}
+
+// get overlay value if overlay key is key or child of key
+func (o *configOverlay) get(key string) interface{} {
+    ok, k := normalizeKey(o.key), normalizeKey(key)
+    if ok == k || strings.HasPrefix(ok, k+".") {
+        return o.val
+    }
+
+    return nil
+}
+
+// normalizeKey lower case key and remove underscores
+func normalizeKey(key string) string {
+    return strings.ToLower(strings.Replace(key, "_", "", -1))
+}
+
+// overlayFlags repeatable --set flag
+type overlayFlags []string
+
+func (o *overlayFlags) String() string {
+    return strings.Join(*o, ",")
+}
+
+func (o *overlayFlags) Set(v string) error {
+    if pos := strings.IndexByte(v, '='); pos <= 0 {
+        return fmt.Errorf("invalid format, expect key=value")
+    }
+
+    *o = append(*o, v)
+    return nil
+}
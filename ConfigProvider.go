@@ -0,0 +1,432 @@
+package pgo
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+    "net/url"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+// configProvider state of a remote provider
+type configProvider struct {
+    provider  IConfigProvider
+    cacheFile string
+    data      map[string]interface{}
+}
+
+// save data to cache file
+func (p *configProvider) save(data map[string]interface{}) error {
+    if len(p.cacheFile) == 0 {
+        return nil
+    }
+
+    content, e := json.Marshal(data)
+    if e != nil {
+        return e
+    }
+
+    if e := os.MkdirAll(filepath.Dir(p.cacheFile), 0755); e != nil {
+        return e
+    }
+
+    // write to temp file and rename to avoid partial file
+    tmpFile := p.cacheFile + ".tmp"
+    if e := ioutil.WriteFile(tmpFile, content, 0644); e != nil {
+        return e
+    }
+
+    return os.Rename(tmpFile, p.cacheFile)
+}
+
+// load data from cache file
+func (p *configProvider) load() (map[string]interface{}, error) {
+    if len(p.cacheFile) == 0 {
+        return nil, fmt.Errorf("no cache file")
+    }
+
+    content, e := ioutil.ReadFile(p.cacheFile)
+    if e != nil {
+        return nil, e
+    }
+
+    var data map[string]interface{}
+    if e := json.Unmarshal(content, &data); e != nil {
+        return nil, e
+    }
+
+    return data, nil
+}
+
+// AddProvider add remote provider, data of provider is fetched
+// immediately, if failed, data saved in cacheFile will be used,
+// if cacheFile is empty or failed to load, panic will occur.
+// provider is watched for changes after StartWatch.
+func (c *Config) AddProvider(provider IConfigProvider, cacheFile string) {
+    p := &configProvider{provider: provider, cacheFile: GetAlias(cacheFile)}
+
+    data, e := provider.Fetch(context.Background(), false)
+    if e == nil {
+        // log component is not ready while adding provider in app init
+        if e := p.save(data); e != nil {
+            fmt.Fprintf(os.Stderr, "Config: failed to save remote config cache, %s\n", e)
+        }
+    } else if cache, e2 := p.load(); e2 == nil {
+        data = cache
+    } else {
+        panic(fmt.Sprintf("Config: failed to fetch remote config, err:%s, cache err:%s", e, e2))
+    }
+
+    p.data = data
+
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    c.providers = append(c.providers, p)
+
+    // drop loaded keys to merge remote config on next loading
+    for name := range data {
+        delete(c.data, name)
+    }
+
+    if c.watchCtx != nil {
+        c.stopWg.Add(1)
+        go c.providerLoop(c.watchCtx, p)
+    }
+}
+
+// providerLoop wait changes of provider and reload changed keys
+func (c *Config) providerLoop(ctx context.Context, p *configProvider) {
+    defer c.stopWg.Done()
+
+    for {
+        data, e := p.provider.Fetch(ctx, true)
+        if ctx.Err() != nil {
+            return
+        }
+
+        if e != nil {
+            GLogger().Warn("Config: failed to fetch remote config, %s", e)
+
+            // wait a moment before retry
+            select {
+            case <-time.After(5 * time.Second):
+                continue
+            case <-ctx.Done():
+                return
+            }
+        }
+
+        c.lock.Lock()
+        old := p.data
+        p.data = data
+        c.lock.Unlock()
+
+        if e := p.save(data); e != nil {
+            GLogger().Warn("Config: failed to save remote config cache, %s", e)
+        }
+
+        // reload changed keys that have been loaded
+        names := make(map[string]bool)
+        for name := range old {
+            names[name] = true
+        }
+
+        for name := range data {
+            names[name] = true
+        }
+
+        for name := range names {
+            c.lock.RLock()
+            _, loaded := c.files[name]
+            c.lock.RUnlock()
+
+            if loaded && !reflect.DeepEqual(old[name], data[name]) {
+                c.reload(name)
+            }
+        }
+    }
+}
+
+// copyConfig deep copy config value
+func copyConfig(v interface{}) interface{} {
+    switch val := v.(type) {
+    case map[string]interface{}:
+        m := make(map[string]interface{}, len(val))
+        for k, vv := range val {
+            m[k] = copyConfig(vv)
+        }
+        return m
+    case []interface{}:
+        s := make([]interface{}, len(val))
+        for i, vv := range val {
+            s[i] = copyConfig(vv)
+        }
+        return s
+    default:
+        return v
+    }
+}
+
+// HttpConfigProvider provider for http json endpoint, the
+// endpoint returns a json object of top-level keys, eg.
+// {"params": {"featureX": true}}, the endpoint is polled
+// every interval when watching, configuration:
+// - class: "@pgo/HttpConfigProvider"
+//   url: "http://127.0.0.1:8080/config"
+//   headers:
+//       Authorization: "Basic xxx"
+//   timeout: "3s"
+//   interval: "30s"
+//   cacheFile: "@app/runtime/config.remote0.json"
+type HttpConfigProvider struct {
+    url      string
+    headers  map[string]string
+    timeout  time.Duration
+    interval time.Duration
+
+    client *http.Client
+}
+
+func (h *HttpConfigProvider) Construct() {
+    h.headers = make(map[string]string)
+    h.timeout = 3 * time.Second
+    h.interval = 30 * time.Second
+}
+
+func (h *HttpConfigProvider) Init() {
+    if len(h.url) == 0 {
+        panic("HttpConfigProvider: url is required")
+    }
+
+    h.client = &http.Client{Timeout: h.timeout}
+}
+
+// SetUrl set url of config endpoint
+func (h *HttpConfigProvider) SetUrl(url string) {
+    h.url = url
+}
+
+// SetHeaders set extra request headers
+func (h *HttpConfigProvider) SetHeaders(headers map[string]interface{}) {
+    for k, v := range headers {
+        h.headers[k] = fmt.Sprint(v)
+    }
+}
+
+// SetTimeout set timeout of each request, default "3s"
+func (h *HttpConfigProvider) SetTimeout(v string) {
+    if timeout, e := time.ParseDuration(v); e != nil {
+        panic(fmt.Sprintf("HttpConfigProvider: parse timeout error, val:%s, err:%s", v, e.Error()))
+    } else {
+        h.timeout = timeout
+    }
+}
+
+// SetInterval set interval to poll endpoint, default "30s"
+func (h *HttpConfigProvider) SetInterval(v string) {
+    if interval, e := time.ParseDuration(v); e != nil || interval <= 0 {
+        panic(fmt.Sprintf("HttpConfigProvider: parse interval error, val:%s, err:%v", v, e))
+    } else {
+        h.interval = interval
+    }
+}
+
+// Fetch get config from endpoint, if wait is true,
+// wait an interval before request.
+func (h *HttpConfigProvider) Fetch(ctx context.Context, wait bool) (map[string]interface{}, error) {
+    if wait {
+        select {
+        case <-time.After(h.interval):
+        case <-ctx.Done():
+            return nil, ctx.Err()
+        }
+    }
+
+    req, e := http.NewRequest(http.MethodGet, h.url, nil)
+    if e != nil {
+        return nil, e
+    }
+
+    for k, v := range h.headers {
+        req.Header.Set(k, v)
+    }
+
+    res, e := h.client.Do(req.WithContext(ctx))
+    if e != nil {
+        return nil, e
+    }
+
+    defer res.Body.Close()
+
+    if res.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("HttpConfigProvider: unexpected status %d from %s", res.StatusCode, h.url)
+    }
+
+    var data map[string]interface{}
+    if e := json.NewDecoder(res.Body).Decode(&data); e != nil {
+        return nil, fmt.Errorf("HttpConfigProvider: failed to decode response from %s, %s", h.url, e)
+    }
+
+    return data, nil
+}
+
+// KvConfigProvider provider for kv store with consul style http
+// api, keys under prefix are mapped to dot separated config keys,
+// eg. "pgo/app/params/featureX" => "params.featureX", values are
+// decoded as json if possible, otherwise used as string. changes
+// are watched by blocking query(long-poll), configuration:
+// - class: "@pgo/KvConfigProvider"
+//   address: "http://127.0.0.1:8500"
+//   prefix: "pgo/app"
+//   token: ""
+//   timeout: "3s"
+//   waitTime: "60s"
+//   cacheFile: "@app/runtime/config.remote1.json"
+type KvConfigProvider struct {
+    address  string
+    prefix   string
+    token    string
+    timeout  time.Duration
+    waitTime time.Duration
+
+    client *http.Client
+    index  uint64 // index of last response
+}
+
+func (k *KvConfigProvider) Construct() {
+    k.address = "http://127.0.0.1:8500"
+    k.timeout = 3 * time.Second
+    k.waitTime = 60 * time.Second
+}
+
+func (k *KvConfigProvider) Init() {
+    if len(k.prefix) == 0 {
+        panic("KvConfigProvider: prefix is required")
+    }
+
+    k.prefix = strings.Trim(k.prefix, "/")
+    k.client = &http.Client{}
+}
+
+// SetAddress set address of kv api, default "http://127.0.0.1:8500"
+func (k *KvConfigProvider) SetAddress(address string) {
+    k.address = strings.TrimRight(address, "/")
+}
+
+// SetPrefix set key prefix of config
+func (k *KvConfigProvider) SetPrefix(prefix string) {
+    k.prefix = prefix
+}
+
+// SetToken set acl token of kv api
+func (k *KvConfigProvider) SetToken(token string) {
+    k.token = token
+}
+
+// SetTimeout set timeout of non-blocking request, default "3s"
+func (k *KvConfigProvider) SetTimeout(v string) {
+    if timeout, e := time.ParseDuration(v); e != nil {
+        panic(fmt.Sprintf("KvConfigProvider: parse timeout error, val:%s, err:%s", v, e.Error()))
+    } else {
+        k.timeout = timeout
+    }
+}
+
+// SetWaitTime set max wait time of blocking query, default "60s"
+func (k *KvConfigProvider) SetWaitTime(v string) {
+    if waitTime, e := time.ParseDuration(v); e != nil || waitTime <= 0 {
+        panic(fmt.Sprintf("KvConfigProvider: parse waitTime error, val:%s, err:%v", v, e))
+    } else {
+        k.waitTime = waitTime
+    }
+}
+
+// Fetch get all keys under prefix, if wait is true, block
+// until keys changed or waitTime elapsed, if no index is
+// returned by last response(eg. stripped by proxy), sleep
+// waitTime before a non-blocking request instead.
+func (k *KvConfigProvider) Fetch(ctx context.Context, wait bool) (map[string]interface{}, error) {
+    query := url.Values{"recurse": {"true"}}
+    timeout := k.timeout
+    if wait && k.index > 0 {
+        query.Set("index", strconv.FormatUint(k.index, 10))
+        query.Set("wait", fmt.Sprintf("%ds", int(k.waitTime/time.Second)))
+        timeout += k.waitTime + k.waitTime/16
+    } else if wait {
+        select {
+        case <-time.After(k.waitTime):
+        case <-ctx.Done():
+            return nil, ctx.Err()
+        }
+    }
+
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+
+    req, e := http.NewRequest(http.MethodGet, k.address+"/v1/kv/"+k.prefix+"?"+query.Encode(), nil)
+    if e != nil {
+        return nil, e
+    }
+
+    if len(k.token) > 0 {
+        req.Header.Set("X-Consul-Token", k.token)
+    }
+
+    res, e := k.client.Do(req.WithContext(ctx))
+    if e != nil {
+        return nil, e
+    }
+
+    defer res.Body.Close()
+
+    var pairs []struct {
+        Key   string
+        Value []byte
+    }
+
+    switch res.StatusCode {
+    case http.StatusOK:
+        if e := json.NewDecoder(res.Body).Decode(&pairs); e != nil {
+            return nil, fmt.Errorf("KvConfigProvider: failed to decode response, %s", e)
+        }
+    case http.StatusNotFound:
+        // no keys under prefix
+    default:
+        return nil, fmt.Errorf("KvConfigProvider: unexpected status %d", res.StatusCode)
+    }
+
+    // reset index if it goes backwards
+    index, _ := strconv.ParseUint(res.Header.Get("X-Consul-Index"), 10, 64)
+    if index < k.index {
+        index = 0
+    }
+
+    k.index = index
+
+    data := make(map[string]interface{})
+    for _, pair := range pairs {
+        key := strings.Trim(strings.TrimPrefix(pair.Key, k.prefix), "/")
+        if len(key) == 0 || pair.Value == nil {
+            continue // skip folder
+        }
+
+        var val interface{}
+        if e := json.Unmarshal(pair.Value, &val); e != nil {
+            val = string(pair.Value)
+        }
+
+        Util.MapSet(data, strings.Replace(key, "/", ".", -1), val)
+    }
+
+    return data, nil
+}
@@ -0,0 +1,236 @@
+package pgo
+
+import (
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/rand"
+    "encoding/base64"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "os"
+    "regexp"
+    "sort"
+    "strings"
+    "sync"
+    "sync/atomic"
+)
+
+const (
+    // SecretMask replacement of secrets in log and dump
+    SecretMask = "******"
+
+    // secrets shorter than this are not masked
+    minSecretLen = 4
+
+    secretEncPrefix = "enc:"
+)
+
+// secret file reference: ${file:/path/to/secret}
+var secretFileRe = regexp.MustCompile(`^\$\{file:([^}]+)\}$`)
+
+// configSecrets resolved secrets of config
+type configSecrets struct {
+    lock   sync.Mutex
+    values map[string]bool
+    masker atomic.Value // *strings.Replacer
+
+    keyOnce sync.Once
+    key     []byte
+    keyErr  error
+}
+
+// resolve secret reference, ok is false if s is not a reference
+func (s *configSecrets) resolve(str string) (secret string, ok bool, err error) {
+    if m := secretFileRe.FindStringSubmatch(str); m != nil {
+        content, e := ioutil.ReadFile(GetAlias(strings.TrimSpace(m[1])))
+        if e != nil {
+            return "", true, fmt.Errorf("failed to read secret file, %s", e)
+        }
+
+        secret = strings.TrimRight(string(content), "\r\n")
+    } else if strings.HasPrefix(str, secretEncPrefix) {
+        if secret, err = s.decrypt(str[len(secretEncPrefix):]); err != nil {
+            return "", true, err
+        }
+    } else {
+        return "", false, nil
+    }
+
+    s.add(secret)
+    return secret, true, nil
+}
+
+// add secret and rebuild masker
+func (s *configSecrets) add(secret string) {
+    if len(secret) < minSecretLen {
+        return
+    }
+
+    s.lock.Lock()
+    defer s.lock.Unlock()
+
+    if s.values == nil {
+        s.values = make(map[string]bool)
+    } else if s.values[secret] {
+        return
+    }
+
+    s.values[secret] = true
+
+    // longer secret is replaced first
+    secrets := make([]string, 0, len(s.values))
+    for v := range s.values {
+        secrets = append(secrets, v)
+    }
+
+    sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+
+    pairs := make([]string, 0, 2*len(secrets))
+    for _, v := range secrets {
+        pairs = append(pairs, v, SecretMask)
+    }
+
+    s.masker.Store(strings.NewReplacer(pairs...))
+}
+
+// mask secrets in str
+func (s *configSecrets) mask(str string) string {
+    if masker, ok := s.masker.Load().(*strings.Replacer); ok {
+        return masker.Replace(str)
+    }
+
+    return str
+}
+
+// loadKey load base64 encoded key from env PgoSecretKey
+// or file specified by env PgoSecretKeyFile
+func (s *configSecrets) loadKey() ([]byte, error) {
+    s.keyOnce.Do(func() {
+        encoded := os.Getenv("PgoSecretKey")
+        if path := os.Getenv("PgoSecretKeyFile"); len(encoded) == 0 && len(path) > 0 {
+            content, e := ioutil.ReadFile(path)
+            if e != nil {
+                s.keyErr = fmt.Errorf("failed to read secret key file, %s", e)
+                return
+            }
+
+            encoded = string(content)
+        }
+
+        if encoded = strings.TrimSpace(encoded); len(encoded) == 0 {
+            s.keyErr = fmt.Errorf("secret key not found, set env PgoSecretKey or PgoSecretKeyFile")
+            return
+        }
+
+        if s.key, s.keyErr = base64.StdEncoding.DecodeString(encoded); s.keyErr != nil {
+            s.keyErr = fmt.Errorf("invalid secret key, %s", s.keyErr)
+        }
+    })
+
+    return s.key, s.keyErr
+}
+
+func (s *configSecrets) aead() (cipher.AEAD, error) {
+    key, e := s.loadKey()
+    if e != nil {
+        return nil, e
+    }
+
+    block, e := aes.NewCipher(key)
+    if e != nil {
+        return nil, fmt.Errorf("invalid secret key, %s", e)
+    }
+
+    return cipher.NewGCM(block)
+}
+
+// decrypt base64 encoded nonce and ciphertext
+func (s *configSecrets) decrypt(encoded string) (string, error) {
+    aead, e := s.aead()
+    if e != nil {
+        return "", e
+    }
+
+    data, e := base64.StdEncoding.DecodeString(encoded)
+    if e != nil || len(data) < aead.NonceSize() {
+        return "", fmt.Errorf("invalid encrypted value")
+    }
+
+    nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+    plain, e := aead.Open(nil, nonce, ciphertext, nil)
+    if e != nil {
+        return "", fmt.Errorf("failed to decrypt value, %s", e)
+    }
+
+    return string(plain), nil
+}
+
+// Encrypt encrypt plain text to "enc:" value for config files,
+// the key is the same used for decryption.
+func (c *Config) Encrypt(plain string) (string, error) {
+    aead, e := c.secrets.aead()
+    if e != nil {
+        return "", e
+    }
+
+    nonce := make([]byte, aead.NonceSize())
+    if _, e := io.ReadFull(rand.Reader, nonce); e != nil {
+        return "", e
+    }
+
+    data := aead.Seal(nonce, nonce, []byte(plain), nil)
+    return secretEncPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Mask replace resolved secrets in str with SecretMask
+func (c *Config) Mask(str string) string {
+    return c.secrets.mask(str)
+}
+
+// Dump get copy of config value by dot separated key,
+// resolved secrets are masked, it's safe for debug output.
+func (c *Config) Dump(key string) interface{} {
+    return c.maskValue(copyConfig(c.Get(key)))
+}
+
+func (c *Config) maskValue(v interface{}) interface{} {
+    switch val := v.(type) {
+    case map[string]interface{}:
+        for k, vv := range val {
+            val[k] = c.maskValue(vv)
+        }
+    case []interface{}:
+        for i, vv := range val {
+            val[i] = c.maskValue(vv)
+        }
+    case string:
+        return c.Mask(val)
+    }
+
+    return v
+}
+
+// resolveSecrets resolve secret references in config value v of
+// key, source is the file or source name for error report.
+func (c *Config) resolveSecrets(source, key string, v interface{}) interface{} {
+    switch val := v.(type) {
+    case map[string]interface{}:
+        for k, vv := range val {
+            val[k] = c.resolveSecrets(source, key+"."+k, vv)
+        }
+    case []interface{}:
+        for i, vv := range val {
+            val[i] = c.resolveSecrets(source, fmt.Sprintf("%s.%d", key, i), vv)
+        }
+    case string:
+        secret, ok, e := c.secrets.resolve(val)
+        if e != nil {
+            panic(&ConfigError{File: source, Key: key, Err: e})
+        } else if ok {
+            return secret
+        }
+    }
+
+    return v
+}
@@ -0,0 +1,397 @@
+package pgo
+
+import (
+    "fmt"
+    "os"
+    "reflect"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ConfigError error of a config key, File is the config
+// source which provides the key or its nearest parent.
+type ConfigError struct {
+    File string
+    Key  string
+    Err  error
+}
+
+func (e *ConfigError) Error() string {
+    return fmt.Sprintf("Config: %s, key:%s, file:%s", e.Err, e.Key, e.File)
+}
+
+func (e *ConfigError) Unwrap() error {
+    return e.Err
+}
+
+// Unmarshal decode config of dot separated key into struct pointed
+// by v, fields are mapped by `config` tag or lower camel case of field
+// name, keys not mapped to any field are reported as errors. tags:
+// default:"10s"       default value if key not exists
+// env:"ADS_TIMEOUT"   value of environment variable, take precedence over config
+// validate:"required,min=1,max=10,enum=a|b"
+// min and max check length for string, slice and map, value for numbers.
+// time.Duration field accepts "30s" or seconds, integer field accepts size
+// like "10MB". each error is a *ConfigError carries key and file path.
+func (c *Config) Unmarshal(key string, v interface{}) error {
+    rv := reflect.ValueOf(v)
+    if rv.Kind() != reflect.Ptr || rv.IsNil() {
+        return fmt.Errorf("Config: Unmarshal require a non-nil pointer, got %T", v)
+    }
+
+    return c.decode(key, c.Get(key), rv.Elem())
+}
+
+// decode config value to rv
+func (c *Config) decode(key string, val interface{}, rv reflect.Value) error {
+    if rv.Type() == durationType {
+        d, e := toDuration(val)
+        if e != nil {
+            return c.error(key, e)
+        }
+
+        rv.SetInt(int64(d))
+        return nil
+    }
+
+    switch rv.Kind() {
+    case reflect.Ptr:
+        if val == nil {
+            return nil
+        }
+
+        if rv.IsNil() {
+            rv.Set(reflect.New(rv.Type().Elem()))
+        }
+
+        return c.decode(key, val, rv.Elem())
+
+    case reflect.Struct:
+        return c.decodeStruct(key, val, rv)
+
+    case reflect.Slice:
+        items, ok := val.([]interface{})
+        if !ok {
+            return c.error(key, fmt.Errorf("expect list, got %T", val))
+        }
+
+        slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+        for i, item := range items {
+            if e := c.decode(fmt.Sprintf("%s.%d", key, i), item, slice.Index(i)); e != nil {
+                return e
+            }
+        }
+
+        rv.Set(slice)
+
+    case reflect.Map:
+        m, ok := val.(map[string]interface{})
+        if !ok || rv.Type().Key().Kind() != reflect.String {
+            return c.error(key, fmt.Errorf("expect map, got %T", val))
+        }
+
+        result := reflect.MakeMapWithSize(rv.Type(), len(m))
+        for k, item := range m {
+            elem := reflect.New(rv.Type().Elem()).Elem()
+            if e := c.decode(key+"."+k, item, elem); e != nil {
+                return e
+            }
+
+            result.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+        }
+
+        rv.Set(result)
+
+    case reflect.Interface:
+        if val != nil {
+            rv.Set(reflect.ValueOf(val))
+        }
+
+    case reflect.String:
+        switch v := val.(type) {
+        case string:
+            rv.SetString(v)
+        case bool, int, int64, float64:
+            rv.SetString(Util.ToString(v))
+        default:
+            return c.error(key, fmt.Errorf("expect string, got %T", val))
+        }
+
+    case reflect.Bool:
+        switch v := val.(type) {
+        case bool:
+            rv.SetBool(v)
+        case string:
+            b, e := strconv.ParseBool(v)
+            if e != nil {
+                return c.error(key, fmt.Errorf("invalid bool: %s", v))
+            }
+
+            rv.SetBool(b)
+        default:
+            return c.error(key, fmt.Errorf("expect bool, got %T", val))
+        }
+
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+        n, e := toInt64(val)
+        if e != nil {
+            return c.error(key, e)
+        } else if rv.OverflowInt(n) {
+            return c.error(key, fmt.Errorf("value %d overflows %s", n, rv.Type()))
+        }
+
+        rv.SetInt(n)
+
+    case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        n, e := toInt64(val)
+        if e != nil {
+            return c.error(key, e)
+        } else if n < 0 || rv.OverflowUint(uint64(n)) {
+            return c.error(key, fmt.Errorf("value %d overflows %s", n, rv.Type()))
+        }
+
+        rv.SetUint(uint64(n))
+
+    case reflect.Float32, reflect.Float64:
+        switch v := val.(type) {
+        case int:
+            rv.SetFloat(float64(v))
+        case int64:
+            rv.SetFloat(float64(v))
+        case float64:
+            rv.SetFloat(v)
+        case string:
+            f, e := strconv.ParseFloat(v, 64)
+            if e != nil {
+                return c.error(key, fmt.Errorf("invalid float: %s", v))
+            }
+
+            rv.SetFloat(f)
+        default:
+            return c.error(key, fmt.Errorf("expect float, got %T", val))
+        }
+
+    default:
+        return c.error(key, fmt.Errorf("unsupported type %s", rv.Type()))
+    }
+
+    return nil
+}
+
+// decodeStruct decode config map to struct fields
+func (c *Config) decodeStruct(key string, val interface{}, rv reflect.Value) error {
+    m, ok := val.(map[string]interface{})
+    if val != nil && !ok {
+        return c.error(key, fmt.Errorf("expect map, got %T", val))
+    }
+
+    used := make(map[string]bool)
+    rt := rv.Type()
+
+    for i := 0; i < rt.NumField(); i++ {
+        field := rt.Field(i)
+        if field.PkgPath != "" {
+            continue // unexported
+        }
+
+        name := field.Tag.Get("config")
+        if name == "-" {
+            continue
+        } else if name == "" {
+            name = strings.ToLower(field.Name[:1]) + field.Name[1:]
+        }
+
+        // match key case-insensitively as Configure does
+        fieldVal, exists := interface{}(nil), false
+        for k, v := range m {
+            if strings.EqualFold(k, name) {
+                fieldVal, exists = v, true
+                used[k] = true
+                break
+            }
+        }
+
+        if env := field.Tag.Get("env"); env != "" {
+            if v, ok := os.LookupEnv(env); ok {
+                fieldVal, exists = v, true
+            }
+        }
+
+        if dft, ok := field.Tag.Lookup("default"); ok && !exists {
+            fieldVal, exists = dft, true
+        }
+
+        fieldKey := key + "." + name
+        if exists {
+            if e := c.decode(fieldKey, fieldVal, rv.Field(i)); e != nil {
+                return e
+            }
+        } else if e := c.decodeMissing(fieldKey, rv.Field(i)); e != nil {
+            return e
+        }
+
+        if rules := field.Tag.Get("validate"); rules != "" {
+            if e := c.validate(fieldKey, rv.Field(i), exists, rules); e != nil {
+                return e
+            }
+        }
+    }
+
+    for k := range m {
+        if !used[k] {
+            return c.error(key+"."+k, fmt.Errorf("unknown key"))
+        }
+    }
+
+    return nil
+}
+
+// decodeMissing visit struct or struct pointer of missing key, so that
+// defaults and rules of nested fields are applied, pointer is set only
+// if any default is applied.
+func (c *Config) decodeMissing(key string, rv reflect.Value) error {
+    switch {
+    case rv.Kind() == reflect.Struct:
+        return c.decodeStruct(key, nil, rv)
+
+    case rv.Kind() == reflect.Ptr && rv.Type().Elem().Kind() == reflect.Struct:
+        if !rv.IsNil() {
+            return c.decodeStruct(key, nil, rv.Elem())
+        }
+
+        elem := reflect.New(rv.Type().Elem())
+        if e := c.decodeStruct(key, nil, elem.Elem()); e != nil {
+            return e
+        }
+
+        if !elem.Elem().IsZero() {
+            rv.Set(elem)
+        }
+    }
+
+    return nil
+}
+
+// validate check field value against rules
+func (c *Config) validate(key string, rv reflect.Value, exists bool, rules string) error {
+    for _, rule := range strings.Split(rules, ",") {
+        name, arg := strings.TrimSpace(rule), ""
+        if pos := strings.IndexByte(name, '='); pos > 0 {
+            name, arg = name[:pos], name[pos+1:]
+        }
+
+        switch name {
+        case "required":
+            if !exists {
+                return c.error(key, fmt.Errorf("key is required"))
+            }
+
+        case "min", "max":
+            limit, e := strconv.ParseFloat(arg, 64)
+            if e != nil {
+                return c.error(key, fmt.Errorf("invalid validate rule: %s", rule))
+            }
+
+            var n float64
+            switch rv.Kind() {
+            case reflect.String, reflect.Slice, reflect.Map:
+                n = float64(rv.Len())
+            case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+                n = float64(rv.Int())
+            case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+                n = float64(rv.Uint())
+            case reflect.Float32, reflect.Float64:
+                n = rv.Float()
+            default:
+                return c.error(key, fmt.Errorf("rule %s not supported for %s", name, rv.Type()))
+            }
+
+            if rv.Type() == durationType {
+                // limit of duration is in seconds
+                n = time.Duration(rv.Int()).Seconds()
+            }
+
+            if name == "min" && n < limit {
+                return c.error(key, fmt.Errorf("value is too small, min:%s", arg))
+            } else if name == "max" && n > limit {
+                return c.error(key, fmt.Errorf("value is too large, max:%s", arg))
+            }
+
+        case "enum":
+            str := fmt.Sprint(rv.Interface())
+            found := false
+            for _, v := range strings.Split(arg, "|") {
+                if v == str {
+                    found = true
+                    break
+                }
+            }
+
+            if !found {
+                return c.error(key, fmt.Errorf("value %s not in enum %s", str, arg))
+            }
+
+        case "":
+        default:
+            return c.error(key, fmt.Errorf("unknown validate rule: %s", rule))
+        }
+    }
+
+    return nil
+}
+
+// error create config error with source of key
+func (c *Config) error(key string, err error) error {
+    return &ConfigError{File: c.source(key), Key: key, Err: err}
+}
+
+// toInt64 convert number, numeric string or size string to int64
+func toInt64(val interface{}) (int64, error) {
+    switch v := val.(type) {
+    case int:
+        return int64(v), nil
+    case int64:
+        return v, nil
+    case float64:
+        if v != float64(int64(v)) {
+            return 0, fmt.Errorf("expect integer, got %v", v)
+        }
+
+        return int64(v), nil
+    case string:
+        if n, e := strconv.ParseInt(v, 10, 64); e == nil {
+            return n, nil
+        }
+
+        n, e := Util.ParseSize(v)
+        if e != nil {
+            return 0, fmt.Errorf("invalid integer or size: %s", v)
+        }
+
+        return n, nil
+    default:
+        return 0, fmt.Errorf("expect integer, got %T", val)
+    }
+}
+
+// toDuration convert duration string or seconds to duration
+func toDuration(val interface{}) (time.Duration, error) {
+    switch v := val.(type) {
+    case string:
+        d, e := time.ParseDuration(v)
+        if e != nil {
+            return 0, fmt.Errorf("invalid duration: %s", v)
+        }
+
+        return d, nil
+    case int, int64, float64:
+        return time.Duration(Util.ToFloat(v) * float64(time.Second)), nil
+    default:
+        return 0, fmt.Errorf("expect duration, got %T", val)
+    }
+}
@@ -0,0 +1,66 @@
+package pgo
+
+import (
+    "errors"
+    "testing"
+    "time"
+)
+
+type testRedisConfig struct {
+    Prefix  string        `default:"ads_"`
+    Timeout time.Duration `default:"1s"`
+}
+
+type testDbConfig struct {
+    Dsn string `validate:"required"`
+}
+
+type testAdsConfig struct {
+    Name  string
+    Redis testRedisConfig
+    Cache *testRedisConfig
+    Db    *testDbConfig
+}
+
+func newTestConfig(key string, val interface{}) *Config {
+    c := &Config{}
+    c.Construct()
+    c.Set(key, val)
+    return c
+}
+
+func TestConfigUnmarshalNestedDefault(t *testing.T) {
+    c := newTestConfig("params", map[string]interface{}{
+        "ads": map[string]interface{}{"name": "ads", "db": map[string]interface{}{"dsn": "x"}},
+    })
+
+    v := &testAdsConfig{}
+    if e := c.Unmarshal("params.ads", v); e != nil {
+        t.Fatalf("unexpected error: %s", e)
+    }
+
+    if v.Redis.Prefix != "ads_" || v.Redis.Timeout != time.Second {
+        t.Errorf("nested default not applied: %+v", v.Redis)
+    }
+
+    if v.Cache == nil || v.Cache.Prefix != "ads_" {
+        t.Errorf("nested default of pointer not applied: %+v", v.Cache)
+    }
+
+    if v.Db == nil || v.Db.Dsn != "x" {
+        t.Errorf("unexpected db: %+v", v.Db)
+    }
+}
+
+func TestConfigUnmarshalNestedRequired(t *testing.T) {
+    c := newTestConfig("params", map[string]interface{}{
+        "ads": map[string]interface{}{"name": "ads"},
+    })
+
+    e := c.Unmarshal("params.ads", &testAdsConfig{})
+
+    var ce *ConfigError
+    if !errors.As(e, &ce) || ce.Key != "params.ads.db.dsn" {
+        t.Fatalf("expect required error of params.ads.db.dsn, got %v", e)
+    }
+}
@@ -0,0 +1,196 @@
+package pgo
+
+import (
+    "context"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "time"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+// configWatcher subscriber of a config key
+type configWatcher struct {
+    key string
+    fn  func(key string, val interface{})
+}
+
+// Watch subscribe changes of dot separated key, fn is called
+// with the new value after config file is reloaded and value
+// of key changed, val is nil if key is removed, eg.
+// Watch("params.featureX", func(key string, val interface{}) {...})
+func (c *Config) Watch(key string, fn func(key string, val interface{})) {
+    // make sure config of key is loaded
+    c.Get(key)
+
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    c.watchers = append(c.watchers, configWatcher{key, fn})
+}
+
+// StartWatch start checking config files every interval,
+// and watching changes of remote providers.
+func (c *Config) StartWatch(interval time.Duration) {
+    if interval <= 0 || c.watchCtx != nil {
+        return
+    }
+
+    c.watchCtx, c.watchStop = context.WithCancel(context.Background())
+    c.stopWg.Add(1)
+    go c.watchLoop(c.watchCtx, interval)
+
+    c.lock.RLock()
+    defer c.lock.RUnlock()
+
+    for _, p := range c.providers {
+        c.stopWg.Add(1)
+        go c.providerLoop(c.watchCtx, p)
+    }
+}
+
+// StopWatch stop checking config files and remote providers
+func (c *Config) StopWatch() {
+    if c.watchCtx != nil {
+        c.watchStop()
+        c.stopWg.Wait()
+        c.watchCtx, c.watchStop = nil, nil
+    }
+}
+
+// Reload check files of loaded keys, re-parse changed
+// keys and notify subscribers of changed values.
+func (c *Config) Reload() {
+    c.lock.RLock()
+    names := make([]string, 0)
+    for name, files := range c.files {
+        if c.changed(name, files) {
+            names = append(names, name)
+        }
+    }
+    c.lock.RUnlock()
+
+    for _, name := range names {
+        c.reload(name)
+    }
+}
+
+func (c *Config) watchLoop(ctx context.Context, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    defer c.stopWg.Done()
+
+    for {
+        select {
+        case <-ticker.C:
+            c.Reload()
+        case <-ctx.Done():
+            return
+        }
+    }
+}
+
+// changed check whether config files of name are
+// added, removed or modified since last loading.
+func (c *Config) changed(name string, files []configFile) bool {
+    // check modification of all files, including files extended or included
+    paths := make([]string, 0, len(files))
+    for _, f := range files {
+        info, e := os.Stat(f.path)
+        if e != nil || !info.ModTime().Equal(f.modTime) || info.Size() != f.size {
+            return true
+        }
+
+        if len(f.from) == 0 {
+            paths = append(paths, f.path)
+        }
+    }
+
+    n := 0
+    for _, path := range c.paths {
+        matches, _ := filepath.Glob(filepath.Join(path, name+".*"))
+        for _, f := range matches {
+            ext := strings.ToLower(filepath.Ext(f))
+            if _, ok := c.parsers[ext[1:]]; !ok {
+                continue
+            }
+
+            if n >= len(paths) || paths[n] != f {
+                return true
+            }
+
+            n++
+        }
+    }
+
+    return n != len(paths)
+}
+
+// reload re-parse config files of name and swap the top-level
+// key, the old config is kept if any file failed to parse.
+func (c *Config) reload(name string) {
+    defer func() {
+        if v := recover(); v != nil {
+            GLogger().Error("Config: failed to reload %s, %s", name, Util.ToString(v))
+        }
+    }()
+
+    data := make(map[string]interface{})
+    files := c.parse(name, data)
+    watchers, values := c.swap(name, data, files)
+
+    GLogger().Info("Config: reloaded %s", name)
+
+    for i, w := range watchers {
+        c.notify(w, values[i])
+    }
+
+    App.GetEventBus().Trigger(EventConfigReload, nil, name)
+}
+
+// swap apply overlays to data and swap the top-level key,
+// subscribers of changed values and new values are returned.
+func (c *Config) swap(name string, data map[string]interface{}, files []configFile) ([]configWatcher, []interface{}) {
+    c.lock.Lock()
+    defer c.lock.Unlock()
+
+    c.overlay(name, data)
+
+    old := map[string]interface{}{name: c.data[name]}
+    if val, ok := data[name]; ok {
+        c.data[name] = val
+    } else {
+        delete(c.data, name)
+    }
+
+    c.files[name] = files
+
+    // collect subscribers of changed values
+    watchers, values := make([]configWatcher, 0), make([]interface{}, 0)
+    for _, w := range c.watchers {
+        if w.key != name && !strings.HasPrefix(w.key, name+".") {
+            continue
+        }
+
+        newVal := Util.MapGet(data, w.key)
+        if !reflect.DeepEqual(Util.MapGet(old, w.key), newVal) {
+            watchers = append(watchers, w)
+            values = append(values, newVal)
+        }
+    }
+
+    return watchers, values
+}
+
+// notify subscriber, panic of subscriber is logged
+func (c *Config) notify(w configWatcher, val interface{}) {
+    defer func() {
+        if v := recover(); v != nil {
+            GLogger().Error("Config: failed to notify %s, %s", w.key, Util.ToString(v))
+        }
+    }()
+
+    w.fn(w.key, val)
+}
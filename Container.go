@@ -1,24 +1,49 @@
 package pgo
 
 import (
+    "fmt"
     "reflect"
     "strings"
     "sync"
 )
 
 type bindItem struct {
-    pool  sync.Pool     // object pool
-    info  interface{}   // binding info
-    zero  reflect.Value // zero value
-    cmIdx int           // construct index
-    imIdx int           // init index
+    pool    sync.Pool     // object pool
+    info    interface{}   // binding info
+    zero    reflect.Value // zero value
+    cmIdx   int           // construct index
+    imIdx   int           // init index
+    injects []injectField // fields to inject
 }
 
-// Container the container component, configuration:
+// injectField field with inject tag, eg.
+// `inject:"redis"`                              component of id
+// `inject:"@pgo/Client/Redis/Adapter"`          object of class
+// `inject:"@pgo/Client/Redis/Adapter,redis2"`   object of class with construct params
+type injectField struct {
+    index  []int
+    name   string
+    class  bool
+    params []interface{}
+}
+
+// Container the container component, if strict is enabled,
+// Configure reports unknown keys and type errors. fields tagged
+// with inject are filled when object is created by Get, the
+// value is a component id or a class, object of class is bound
+// to the context if exists, eg.
+// type Welcome struct {
+//     pgo.Controller
+//     Cache *Memory.Client  `inject:"memory"`
+//     Redis *Redis.Adapter  `inject:"@pgo/Client/Redis/Adapter"`
+// }
+// configuration:
 // container:
 //     enablePool: true
+//     strict: false
 type Container struct {
     enablePool bool
+    strict     bool
     items      map[string]*bindItem
 }
 
@@ -32,6 +57,11 @@ func (c *Container) SetEnablePool(enable bool) {
     c.enablePool = enable
 }
 
+// SetStrict set whether to report unknown keys and type errors, default is disabled.
+func (c *Container) SetStrict(strict bool) {
+    c.strict = strict
+}
+
 // Bind bind template object to class,
 // param i must be a pointer of struct.
 func (c *Container) Bind(i interface{}) {
@@ -62,6 +92,9 @@ func (c *Container) Bind(i interface{}) {
         }
     }
 
+    // get fields to inject
+    item.injects = injectFields(rt, nil)
+
     // get class name
     pkgPath := rt.PkgPath()
 
@@ -106,19 +139,33 @@ func (c *Container) GetType(name string) reflect.Type {
     panic("Container: class not found, " + name)
 }
 
+// GetE get new class object, error is returned instead of panic,
+// the error of unknown class is ErrNotFound, panics occurred in
+// Construct, Configure and Init are returned as error.
+func (c *Container) GetE(name string, config map[string]interface{}, params ...interface{}) (rv reflect.Value, err error) {
+    defer func() {
+        if v := recover(); v != nil {
+            rv, err = reflect.Value{}, ToError(v)
+        }
+    }()
+
+    return c.Get(name, config, params...), nil
+}
+
 // Get get new class object. name is class name, config is properties map,
 // params is optional construct parameters.
 func (c *Container) Get(name string, config map[string]interface{}, params ...interface{}) reflect.Value {
     item, ok := c.items[name]
     if !ok {
-        panic("Container: class not found, " + name)
+        panic(NewError(ErrNotFound, nil, "Container: class not found, %s", name))
     }
 
     // get new object from pool
     rv := item.pool.Get().(reflect.Value)
 
+    var ctx *Context
     if pl := len(params); pl > 0 {
-        if ctx, ok := params[pl-1].(*Context); ok {
+        if ctx, _ = params[pl-1].(*Context); ctx != nil {
             if c.enablePool {
                 // reset properties
                 rv.Elem().Set(item.zero)
@@ -134,6 +181,11 @@ func (c *Container) Get(name string, config map[string]interface{}, params ...in
         }
     }
 
+    // inject dependencies before construct
+    if len(item.injects) > 0 {
+        c.inject(rv.Elem(), item.injects, ctx)
+    }
+
     // call Construct([arg1, arg2, ...])
     if item.cmIdx != -1 {
         if cm := rv.Method(item.cmIdx); cm.IsValid() {
@@ -169,3 +221,68 @@ func (c *Container) Put(name string, rv reflect.Value) {
 
     panic("Container: class not found, " + name)
 }
+
+// inject fill fields of struct rv with components or
+// objects of class, objects are bound to ctx if not nil.
+func (c *Container) inject(rv reflect.Value, injects []injectField, ctx *Context) {
+    for _, f := range injects {
+        var dep interface{}
+        if !f.class {
+            dep = App.Get(f.name)
+        } else if ctx != nil {
+            params := make([]interface{}, 0, len(f.params)+1)
+            dep = CreateObject(f.name, append(append(params, f.params...), ctx)...)
+        } else {
+            dep = CreateObject(f.name, f.params...)
+        }
+
+        field := rv.FieldByIndex(f.index)
+        dv := reflect.ValueOf(dep)
+        if !dv.IsValid() || !dv.Type().AssignableTo(field.Type()) {
+            panic(fmt.Sprintf("Container: inject type mismatch, field:%s, need:%s, got:%T", rv.Type().FieldByIndex(f.index).Name, field.Type(), dep))
+        }
+
+        field.Set(dv)
+    }
+}
+
+// injectFields get fields with inject tag of struct type rt,
+// fields of embedded struct are included.
+func injectFields(rt reflect.Type, index []int) []injectField {
+    injects := make([]injectField, 0)
+    for i := 0; i < rt.NumField(); i++ {
+        field := rt.Field(i)
+        fieldIndex := append(append([]int{}, index...), i)
+
+        tag, ok := field.Tag.Lookup("inject")
+        if !ok {
+            if field.Anonymous && field.Type.Kind() == reflect.Struct {
+                injects = append(injects, injectFields(field.Type, fieldIndex)...)
+            }
+            continue
+        }
+
+        if field.PkgPath != "" {
+            panic(fmt.Sprintf("Container: inject field must be exported, %s.%s", rt.Name(), field.Name))
+        }
+
+        if k := field.Type.Kind(); k != reflect.Ptr && k != reflect.Interface {
+            panic(fmt.Sprintf("Container: inject field must be pointer or interface, %s.%s", rt.Name(), field.Name))
+        }
+
+        parts := strings.Split(tag, ",")
+        name := strings.TrimSpace(parts[0])
+        if len(name) == 0 {
+            panic(fmt.Sprintf("Container: empty inject tag, %s.%s", rt.Name(), field.Name))
+        }
+
+        f := injectField{index: fieldIndex, name: name, class: strings.ContainsRune(name, '/')}
+        for _, p := range parts[1:] {
+            f.params = append(f.params, strings.TrimSpace(p))
+        }
+
+        injects = append(injects, f)
+    }
+
+    return injects
+}
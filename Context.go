@@ -54,11 +54,13 @@ func (c *Context) process(plugins []IPlugin) {
     c.index = -1
     c.Profiler.reset()
     c.Logger.init(App.GetName(), logId, App.GetLog())
+    c.Logger.levels = App.GetLog().escalate(c)
 
     // finish response
     defer c.finish()
 
     // process request
+    App.GetEventBus().Trigger(EventRequestBefore, c)
     c.Next()
 
 }
@@ -67,20 +69,14 @@ func (c *Context) process(plugins []IPlugin) {
 func (c *Context) finish() {
     // process unhandled panic
     if v := recover(); v != nil {
-        status := http.StatusInternalServerError
-        switch e := v.(type) {
-        case *Exception:
-            status = e.GetStatus()
-            c.End(status, []byte(App.GetStatus().GetText(status, c, e.GetMessage())))
-        default:
-            c.End(status, []byte(http.StatusText(status)))
-        }
-
-        c.Error("%s, trace[%s]", Util.ToString(v), Util.PanicTrace(TraceMaxDepth, false))
+        App.GetEventBus().triggerSafe(EventPanic, c, v)
+        App.GetErrorHandler().Handle(c, v, "text")
+        c.Error("%s", panicTrace(v))
     }
 
     // write header if not yet
     c.response.finish()
+    App.GetEventBus().triggerSafe(EventRequestAfter, c)
 
     // write access log
     if c.server.enableAccessLog {
@@ -135,6 +131,7 @@ func (c *Context) Copy() *Context {
     cp.plugins = nil
     cp.index = MaxPlugins
     cp.objects = nil
+    cp.Logger.debugLines = nil
     return &cp
 }
 
@@ -487,6 +484,11 @@ func (c *Context) End(status int, data []byte) {
         c.SetHeader("X-Log-Id", c.Logger.logId)
         c.SetHeader("X-Cost-Time", fmt.Sprintf("%dms", c.GetElapseMs()))
 
+        // return log lines of escalated request
+        for _, line := range c.Logger.debugLines {
+            c.output.Header().Add("X-Debug-Log", line)
+        }
+
         c.output.WriteHeader(status)
         c.output.Write(data)
     } else if len(data) > 0 {
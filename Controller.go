@@ -6,8 +6,6 @@ import (
     "fmt"
     "net/http"
     "reflect"
-
-    "github.com/pinguo/pgo/Util"
 )
 
 // Controller the base class of web and cmd controller
@@ -43,18 +41,12 @@ func (c *Controller) BeforeAction(action string) {
 func (c *Controller) AfterAction(action string) {
 }
 
-// HandlePanic process unhandled action panic
+// HandlePanic process unhandled action panic,
+// error response is rendered by error handler.
 func (c *Controller) HandlePanic(v interface{}) {
-    status := http.StatusInternalServerError
-    switch e := v.(type) {
-    case *Exception:
-        status = e.GetStatus()
-        c.OutputJson(EmptyObject, status, e.GetMessage())
-    default:
-        c.OutputJson(EmptyObject, status)
-    }
-
-    c.GetContext().Error("%s, trace[%s]", Util.ToString(v), Util.PanicTrace(TraceMaxDepth, false))
+    ctx := c.GetContext()
+    App.GetErrorHandler().Handle(ctx, v, "json")
+    ctx.Error("%s", panicTrace(v))
 }
 
 // Redirect output redirect response
@@ -0,0 +1,219 @@
+package pgo
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "reflect"
+    "strings"
+    "sync"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+// ErrorHandlerFunc custom handler of error response
+type ErrorHandlerFunc func(ctx *Context, e *Exception)
+
+// ErrorHandler the error handler component, render error response of
+// unhandled panic and unresolved route. custom handlers can be registered
+// by status code or error type, otherwise error is rendered as view, json
+// or text, the format is negotiated by Accept header if negotiate is true,
+// and falls back to format or the default format of caller(json for panic
+// of controller, text for others), view is rendered only if configured
+// for the status, view data has fields status, code, message and details.
+// configuration:
+// errorHandler:
+//     negotiate: true
+//     format: ""
+//     views:
+//         404: "@view/error/404.html"
+//         500: "@view/error/500.html"
+type ErrorHandler struct {
+    negotiate bool
+    format    string
+    views     map[int]string
+
+    statusHandlers map[int]ErrorHandlerFunc
+    typeHandlers   map[reflect.Type]ErrorHandlerFunc
+    lock           sync.RWMutex
+}
+
+func (h *ErrorHandler) Construct() {
+    h.negotiate = true
+    h.views = make(map[int]string)
+    h.statusHandlers = make(map[int]ErrorHandlerFunc)
+    h.typeHandlers = make(map[reflect.Type]ErrorHandlerFunc)
+}
+
+// SetNegotiate set whether to negotiate format by Accept header, default true
+func (h *ErrorHandler) SetNegotiate(negotiate bool) {
+    h.negotiate = negotiate
+}
+
+// SetFormat set default format, json, html or text,
+// default "" means the default format of caller.
+func (h *ErrorHandler) SetFormat(format string) {
+    switch format {
+    case "", "json", "html", "text":
+        h.format = format
+    default:
+        panic(fmt.Sprintf("ErrorHandler: invalid format, %s", format))
+    }
+}
+
+// SetViews set views of error response by status
+func (h *ErrorHandler) SetViews(views map[string]interface{}) {
+    for k, v := range views {
+        h.views[Util.ToInt(k)] = Util.ToString(v)
+    }
+}
+
+// HandleStatus register handler for status code
+func (h *ErrorHandler) HandleStatus(status int, fn ErrorHandlerFunc) {
+    h.lock.Lock()
+    defer h.lock.Unlock()
+
+    h.statusHandlers[status] = fn
+}
+
+// HandleType register handler for error type, v is value of
+// the type, the handler is called if exception or any error
+// of its cause chain has the type, eg.
+// HandleType((*MyError)(nil), func(ctx *pgo.Context, e *pgo.Exception) {...})
+func (h *ErrorHandler) HandleType(v interface{}, fn ErrorHandlerFunc) {
+    h.lock.Lock()
+    defer h.lock.Unlock()
+
+    h.typeHandlers[reflect.TypeOf(v)] = fn
+}
+
+// Handle render error response of v, v is panic value or error,
+// value other than exception is handled as exception with status
+// 500, format is the default format if not negotiated or configured,
+// error is rendered as text if custom handler, json or view panics.
+func (h *ErrorHandler) Handle(ctx *Context, v interface{}, format string) {
+    e, ok := v.(*Exception)
+    if !ok {
+        e = WrapException(ToError(v), http.StatusInternalServerError)
+        e.stack = Util.PanicTrace(TraceMaxDepth, false)
+    }
+
+    if fn := h.getHandler(e); fn != nil && h.call(ctx, func() { fn(ctx, e) }) {
+        return
+    }
+
+    var render func()
+    switch h.getFormat(ctx, e, format) {
+    case "json":
+        render = func() { h.RenderJson(ctx, e) }
+    case "html":
+        render = func() { h.RenderView(ctx, h.views[e.GetStatus()], e) }
+    }
+
+    // fall back to text if json or view failed to render
+    if render == nil || !h.call(ctx, render) {
+        h.RenderText(ctx, e)
+    }
+}
+
+// RenderJson render exception as json envelope, the status field
+// is app error code(or http status if no code), details of exception
+// is rendered as the details field if not empty.
+func (h *ErrorHandler) RenderJson(ctx *Context, e *Exception) {
+    code := e.GetStatus()
+    if e.GetCode() != 0 {
+        code = e.GetCode()
+    }
+
+    body := map[string]interface{}{
+        "status":  code,
+        "message": e.GetText(ctx),
+        "data":    EmptyObject,
+    }
+
+    if details := e.GetDetails(); len(details) > 0 {
+        body["details"] = details
+    }
+
+    output, err := json.Marshal(body)
+    if err != nil {
+        delete(body, "details")
+        output, _ = json.Marshal(body)
+    }
+
+    ctx.PushLog("status", code)
+    ctx.SetHeader("Content-Type", "application/json; charset=utf-8")
+    ctx.End(e.GetStatus(), output)
+}
+
+// RenderView render exception with view
+func (h *ErrorHandler) RenderView(ctx *Context, view string, e *Exception) {
+    data := map[string]interface{}{
+        "status":  e.GetStatus(),
+        "code":    e.GetCode(),
+        "message": e.GetText(ctx),
+        "details": e.GetDetails(),
+    }
+
+    output := App.GetView().Render(view, data)
+    ctx.SetHeader("Content-Type", "text/html; charset=utf-8")
+    ctx.End(e.GetStatus(), output)
+}
+
+// RenderText render exception as plain text
+func (h *ErrorHandler) RenderText(ctx *Context, e *Exception) {
+    ctx.SetHeader("Content-Type", "text/plain; charset=utf-8")
+    ctx.End(e.GetStatus(), []byte(e.GetText(ctx)))
+}
+
+// get handler by type of error chain, then by status
+func (h *ErrorHandler) getHandler(e *Exception) ErrorHandlerFunc {
+    h.lock.RLock()
+    defer h.lock.RUnlock()
+
+    if len(h.typeHandlers) > 0 {
+        for err := error(e); err != nil; err = errors.Unwrap(err) {
+            if fn, ok := h.typeHandlers[reflect.TypeOf(err)]; ok {
+                return fn
+            }
+        }
+    }
+
+    return h.statusHandlers[e.GetStatus()]
+}
+
+// call custom handler or renderer, return false if it panics
+func (h *ErrorHandler) call(ctx *Context, fn func()) (ok bool) {
+    defer func() {
+        if v := recover(); v != nil {
+            ctx.Error("ErrorHandler: failed to handle error, %s", panicTrace(v))
+        }
+    }()
+
+    fn()
+    return true
+}
+
+func (h *ErrorHandler) getFormat(ctx *Context, e *Exception, format string) string {
+    _, hasView := h.views[e.GetStatus()]
+
+    if h.negotiate {
+        accept := ctx.GetHeader("Accept", "")
+        if strings.Contains(accept, "text/html") && hasView {
+            return "html"
+        } else if strings.Contains(accept, "json") {
+            return "json"
+        }
+    }
+
+    if len(h.format) > 0 {
+        format = h.format
+    }
+
+    if format == "html" && !hasView {
+        format = "text"
+    }
+
+    return format
+}
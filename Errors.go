@@ -0,0 +1,71 @@
+package pgo
+
+import (
+    "errors"
+    "fmt"
+    "net"
+)
+
+// typed errors of error-returning APIs, check by errors.Is, eg.
+// if _, e := redis.GetE("key"); errors.Is(e, pgo.ErrTimeout) {...}
+var (
+    ErrNotFound = errors.New("not found")
+    ErrNoServer = errors.New("no server available")
+    ErrTimeout  = errors.New("timeout")
+    ErrNetwork  = errors.New("network error")
+    ErrProtocol = errors.New("protocol error")
+)
+
+// NewError create typed error of kind, the message is kept the
+// same as the panic message of panic-driven APIs, cause is the
+// underlying error and can be nil.
+func NewError(kind, cause error, format string, args ...interface{}) *Error {
+    return &Error{kind: kind, cause: cause, message: fmt.Sprintf(format, args...)}
+}
+
+// NewNetError create error of network operation, kind is ErrTimeout
+// if cause is a timeout error, otherwise kind is ErrNetwork.
+func NewNetError(cause error, format string, args ...interface{}) *Error {
+    kind := ErrNetwork
+    if ne, ok := cause.(net.Error); ok && ne.Timeout() {
+        kind = ErrTimeout
+    }
+
+    return NewError(kind, cause, format, args...)
+}
+
+// Error typed error with kind and cause
+type Error struct {
+    kind    error
+    cause   error
+    message string
+}
+
+// Error implement error interface
+func (e *Error) Error() string {
+    return e.message
+}
+
+// Is report whether kind of error is target
+func (e *Error) Is(target error) bool {
+    return e.kind != nil && e.kind == target
+}
+
+// Unwrap get cause of error
+func (e *Error) Unwrap() error {
+    return e.cause
+}
+
+// ToError convert recovered value to error
+func ToError(v interface{}) error {
+    switch val := v.(type) {
+    case nil:
+        return nil
+    case error:
+        return val
+    case string:
+        return errors.New(val)
+    default:
+        return fmt.Errorf("%v", val)
+    }
+}
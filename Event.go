@@ -0,0 +1,215 @@
+package pgo
+
+import (
+    "sort"
+    "strings"
+    "sync"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+// built-in events triggered by framework, args of event:
+// app.start, app.stop: no args, ctx is nil
+// request.before, request.after: no args
+// action.before, action.after: controller, action id
+// panic: recovered value
+// config.reload: top-level config key
+// pool.down, pool.up: client name(redis or memcache), server address, ctx is nil
+const (
+    EventAppStart      = "app.start"
+    EventAppStop       = "app.stop"
+    EventRequestBefore = "request.before"
+    EventRequestAfter  = "request.after"
+    EventActionBefore  = "action.before"
+    EventActionAfter   = "action.after"
+    EventPanic         = "panic"
+    EventConfigReload  = "config.reload"
+    EventPoolDown      = "pool.down"
+    EventPoolUp        = "pool.up"
+)
+
+// EventFunc adapter to use ordinary function as event listener
+type EventFunc func(event string, ctx *Context, args ...interface{})
+
+// HandleEvent implement IEvent interface
+func (f EventFunc) HandleEvent(event string, ctx *Context, args ...interface{}) {
+    f(event, ctx, args...)
+}
+
+type eventListener struct {
+    id       int
+    pattern  string
+    listener IEvent
+    priority int
+    async    bool
+}
+
+// EventBus the application event bus, listeners are registered
+// by event name or wildcard pattern, "*" in pattern matches any
+// characters, eg. "request.*", "*.after" or "*". listeners are
+// called in order of priority, higher priority is called first,
+// listeners of the same priority are called in order of registry.
+// panic of sync listener is not recovered, so a sync listener of
+// request events can abort the request by panic of exception, but
+// panic of panic, action.after and request.after listeners is logged
+// since they are triggered after the action. async listener is called
+// in new goroutine with nil ctx(ctx is reused after request finished),
+// and panic of async listener is logged.
+type EventBus struct {
+    listeners []*eventListener
+    cache     map[string][]*eventListener
+    lastId    int
+    lock      sync.RWMutex
+}
+
+func (b *EventBus) Construct() {
+    b.listeners = make([]*eventListener, 0)
+    b.cache = make(map[string][]*eventListener)
+}
+
+// On register sync listener of event pattern, priority
+// is optional and default 0, listener id is returned.
+func (b *EventBus) On(pattern string, listener IEvent, priority ...int) int {
+    return b.add(pattern, listener, false, priority...)
+}
+
+// OnAsync register async listener of event pattern, priority
+// is optional and default 0, listener id is returned.
+func (b *EventBus) OnAsync(pattern string, listener IEvent, priority ...int) int {
+    return b.add(pattern, listener, true, priority...)
+}
+
+// Off remove listener by listener id
+func (b *EventBus) Off(id int) {
+    b.lock.Lock()
+    defer b.lock.Unlock()
+
+    for i, l := range b.listeners {
+        if l.id == id {
+            b.listeners = append(b.listeners[:i], b.listeners[i+1:]...)
+            b.cache = make(map[string][]*eventListener)
+            break
+        }
+    }
+}
+
+// HasListener check whether event has any listener
+func (b *EventBus) HasListener(event string) bool {
+    return len(b.getListeners(event)) > 0
+}
+
+// Trigger trigger event with ctx and args, ctx can be nil,
+// ctx is not passed to async listeners.
+func (b *EventBus) Trigger(event string, ctx *Context, args ...interface{}) {
+    for _, l := range b.getListeners(event) {
+        if l.async {
+            go b.callAsync(l, event, args)
+        } else {
+            l.listener.HandleEvent(event, ctx, args...)
+        }
+    }
+}
+
+// triggerSafe trigger event in deferred handler of request, panic
+// of sync listener is logged and the rest listeners are still called.
+func (b *EventBus) triggerSafe(event string, ctx *Context, args ...interface{}) {
+    for _, l := range b.getListeners(event) {
+        if l.async {
+            go b.callAsync(l, event, args)
+        } else {
+            b.callSafe(l, event, ctx, args)
+        }
+    }
+}
+
+func (b *EventBus) add(pattern string, listener IEvent, async bool, priority ...int) int {
+    b.lock.Lock()
+    defer b.lock.Unlock()
+
+    priority = append(priority, 0)
+    b.lastId++
+    b.listeners = append(b.listeners, &eventListener{
+        id:       b.lastId,
+        pattern:  pattern,
+        listener: listener,
+        priority: priority[0],
+        async:    async,
+    })
+
+    sort.SliceStable(b.listeners, func(i, j int) bool {
+        return b.listeners[i].priority > b.listeners[j].priority
+    })
+
+    b.cache = make(map[string][]*eventListener)
+    return b.lastId
+}
+
+// getListeners get listeners of event, matched listeners are cached
+func (b *EventBus) getListeners(event string) []*eventListener {
+    b.lock.RLock()
+    listeners, ok := b.cache[event]
+    b.lock.RUnlock()
+
+    if ok {
+        return listeners
+    }
+
+    b.lock.Lock()
+    defer b.lock.Unlock()
+
+    listeners = make([]*eventListener, 0)
+    for _, l := range b.listeners {
+        if eventMatch(l.pattern, event) {
+            listeners = append(listeners, l)
+        }
+    }
+
+    b.cache[event] = listeners
+    return listeners
+}
+
+func (b *EventBus) callAsync(l *eventListener, event string, args []interface{}) {
+    defer func() {
+        if v := recover(); v != nil {
+            GLogger().Error("EventBus: failed to handle event %s, %s, trace[%s]",
+                event, Util.ToString(v), Util.PanicTrace(TraceMaxDepth, false))
+        }
+    }()
+
+    l.listener.HandleEvent(event, nil, args...)
+}
+
+func (b *EventBus) callSafe(l *eventListener, event string, ctx *Context, args []interface{}) {
+    defer func() {
+        if v := recover(); v != nil {
+            ctx.Error("EventBus: failed to handle event %s, %s", event, panicTrace(v))
+        }
+    }()
+
+    l.listener.HandleEvent(event, ctx, args...)
+}
+
+// eventMatch check whether event matches pattern, "*" matches any characters
+func eventMatch(pattern, event string) bool {
+    parts := strings.Split(pattern, "*")
+    if len(parts) == 1 {
+        return pattern == event
+    }
+
+    last := len(parts) - 1
+    if !strings.HasPrefix(event, parts[0]) || !strings.HasSuffix(event[len(parts[0]):], parts[last]) {
+        return false
+    }
+
+    event = event[len(parts[0]) : len(event)-len(parts[last])]
+    for _, part := range parts[1:last] {
+        pos := strings.Index(event, part)
+        if pos < 0 {
+            return false
+        }
+
+        event = event[pos+len(part):]
+    }
+
+    return true
+}
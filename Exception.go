@@ -2,10 +2,23 @@ package pgo
 
 import (
     "fmt"
+    "net/http"
+
+    "github.com/pinguo/pgo/Util"
 )
 
-// NewException create new exception with status and message
+// NewException create new exception with status and message,
+// status is http status, the stack of caller is captured.
 func NewException(status int, msg ...interface{}) *Exception {
+    return newException(nil, status, msg...)
+}
+
+// WrapException create new exception with cause, status and message
+func WrapException(cause error, status int, msg ...interface{}) *Exception {
+    return newException(cause, status, msg...)
+}
+
+func newException(cause error, status int, msg ...interface{}) *Exception {
     message := ""
     if len(msg) == 1 {
         message = msg[0].(string)
@@ -13,13 +26,60 @@ func NewException(status int, msg ...interface{}) *Exception {
         message = fmt.Sprintf(msg[0].(string), msg[1:]...)
     }
 
-    return &Exception{status, message}
+    return &Exception{
+        status:  status,
+        message: message,
+        cause:   cause,
+        stack:   Util.CallerTrace(2, TraceMaxDepth, false),
+    }
 }
 
-// Exception panic as exception
+// Exception panic as exception, exception carries http status,
+// app error code, i18n message key, details, cause and stack, eg.
+// panic(pgo.NewException(http.StatusNotFound, "user %d not found", uid).
+//     WithCode(10404).WithKey("user.notFound", uid).WithDetail("uid", uid))
 type Exception struct {
     status  int
+    code    int
     message string
+    key     string
+    params  []interface{}
+    details map[string]interface{}
+    cause   error
+    stack   string
+}
+
+// WithCode set app error code, default 0 means no code,
+// code is rendered as the status field of json output.
+func (e *Exception) WithCode(code int) *Exception {
+    e.code = code
+    return e
+}
+
+// WithKey set i18n message key and params, message is
+// translated by key and used as output message.
+func (e *Exception) WithKey(key string, params ...interface{}) *Exception {
+    e.key, e.params = key, params
+    return e
+}
+
+// WithDetail add detail of exception
+func (e *Exception) WithDetail(name string, value interface{}) *Exception {
+    if e.details == nil {
+        e.details = make(map[string]interface{})
+    }
+
+    e.details[name] = value
+    return e
+}
+
+// WithDetails add details of exception
+func (e *Exception) WithDetails(details map[string]interface{}) *Exception {
+    for k, v := range details {
+        e.WithDetail(k, v)
+    }
+
+    return e
 }
 
 // GetStatus get exception status code
@@ -27,12 +87,97 @@ func (e *Exception) GetStatus() int {
     return e.status
 }
 
+// GetCode get app error code
+func (e *Exception) GetCode() int {
+    return e.code
+}
+
 // GetMessage get exception message string
 func (e *Exception) GetMessage() string {
     return e.message
 }
 
-// Error implement error interface
+// GetKey get i18n message key
+func (e *Exception) GetKey() string {
+    return e.key
+}
+
+// GetDetails get details of exception
+func (e *Exception) GetDetails() map[string]interface{} {
+    return e.details
+}
+
+// GetCause get cause of exception
+func (e *Exception) GetCause() error {
+    return e.cause
+}
+
+// GetStack get stack captured when exception created
+func (e *Exception) GetStack() string {
+    return e.stack
+}
+
+// GetText get output message of exception, translate by key if key is set,
+// otherwise get status text of code(or status) with message as default.
+func (e *Exception) GetText(ctx *Context) string {
+    if len(e.key) > 0 {
+        lang := ""
+        if ctx != nil {
+            lang = ctx.GetHeader("Accept-Language", "")
+        }
+
+        if txt := App.GetI18n().Translate(e.key, lang, e.params...); txt != e.key || len(e.message) == 0 {
+            return txt
+        }
+
+        return e.message
+    }
+
+    if e.code == 0 {
+        return App.GetStatus().GetText(e.status, ctx, e.message)
+    }
+
+    message := e.message
+    if len(message) == 0 {
+        message = http.StatusText(e.status)
+    }
+
+    return App.GetStatus().GetText(e.code, ctx, message)
+}
+
+// Error implement error interface, message of cause is appended
 func (e *Exception) Error() string {
-    return fmt.Sprintf("exception: %d, message: %s", e.status, e.message)
+    msg := fmt.Sprintf("exception: %d, message: %s", e.status, e.message)
+    if e.code != 0 {
+        msg = fmt.Sprintf("exception: %d, code: %d, message: %s", e.status, e.code, e.message)
+    }
+
+    if e.cause != nil {
+        msg += ", cause: " + e.cause.Error()
+    }
+
+    return msg
+}
+
+// Unwrap get cause of exception
+func (e *Exception) Unwrap() error {
+    return e.cause
+}
+
+// Is report whether exception has the same status and code as
+// target exception, so exceptions can be predefined and checked
+// by errors.Is, eg. errors.Is(e, ErrUserNotFound)
+func (e *Exception) Is(target error) bool {
+    t, ok := target.(*Exception)
+    return ok && t.status == e.status && t.code == e.code
+}
+
+// panicTrace get log string of recovered panic, stack of
+// exception is used instead of the stack of recover.
+func panicTrace(v interface{}) string {
+    if e, ok := v.(*Exception); ok && len(e.stack) > 0 {
+        return fmt.Sprintf("%s, trace[%s]", e.Error(), e.stack)
+    }
+
+    return fmt.Sprintf("%s, trace[%s]", Util.ToString(v), Util.PanicTrace(TraceMaxDepth, false))
 }
@@ -2,6 +2,7 @@ package pgo
 
 import (
     "fmt"
+    "os"
     "reflect"
     "regexp"
     "strings"
@@ -45,23 +46,62 @@ var (
 type Map map[string]interface{}
 
 func init() {
-    // initialize app
-    ConstructAndInit(App, nil)
+    // construct app and bind core object before initializing
+    // app, remote config providers are created in App.Init.
+    App.Construct()
 
-    // bind core object
     App.container.Bind(&Router{})
     App.container.Bind(&Log{})
     App.container.Bind(&ConsoleTarget{})
     App.container.Bind(&FileTarget{})
+    App.container.Bind(&SyslogTarget{})
+    App.container.Bind(&StreamTarget{})
+    App.container.Bind(&HttpTarget{})
     App.container.Bind(&Status{})
     App.container.Bind(&I18n{})
     App.container.Bind(&View{})
+    App.container.Bind(&ErrorHandler{})
     App.container.Bind(&Gzip{})
     App.container.Bind(&File{})
+    App.container.Bind(&HttpConfigProvider{})
+    App.container.Bind(&KvConfigProvider{})
+
+    // initialize app
+    App.Init()
 }
 
-// Run run app
+// Run run app, if --check-config specified, check config
+// of all envs and exit, exit code is non-zero on errors.
+// if --explain-config specified, print sources of config
+// key of running env and exit. configured components are
+// started before serving, exit code is non-zero on errors.
 func Run() {
+    if len(App.explainConfig) > 0 {
+        fmt.Print(App.GetConfig().Explain(App.explainConfig))
+        os.Exit(0)
+    }
+
+    if App.checkConfig {
+        errs := App.CheckConfig()
+        for _, e := range errs {
+            fmt.Fprintln(os.Stderr, e)
+        }
+
+        if len(errs) > 0 {
+            os.Exit(1)
+        }
+
+        fmt.Println("config check passed")
+        os.Exit(0)
+    }
+
+    if e := App.Start(); e != nil {
+        GLogger().Error("%s", e)
+        App.GetLog().Flush()
+        fmt.Fprintln(os.Stderr, e)
+        os.Exit(1)
+    }
+
     App.GetServer().Serve()
 }
 
@@ -116,6 +156,17 @@ func GetAlias(alias string) string {
 // treated as properties of the object to be created,
 // params is optional parameters for Construct method.
 func CreateObject(class interface{}, params ...interface{}) interface{} {
+    obj, e := TryCreateObject(class, params...)
+    if e != nil {
+        panic(e)
+    }
+
+    return obj
+}
+
+// TryCreateObject create object like CreateObject, error is
+// returned instead of panic, error of unknown class is ErrNotFound.
+func TryCreateObject(class interface{}, params ...interface{}) (interface{}, error) {
     var className string
     var config map[string]interface{}
 
@@ -123,21 +174,27 @@ func CreateObject(class interface{}, params ...interface{}) interface{} {
     case string:
         className = v
     case map[string]interface{}:
-        if _, ok := v["class"]; !ok {
-            panic(`CreateObject: class configuration require "class" field`)
+        name, ok := v["class"].(string)
+        if !ok {
+            return nil, fmt.Errorf(`CreateObject: class configuration require "class" field`)
         }
 
-        className = v["class"].(string)
+        className = name
         config = v
     default:
-        panic(fmt.Sprintf("CreateObject: unsupported class type: %T", class))
+        return nil, fmt.Errorf("CreateObject: unsupported class type: %T", class)
     }
 
     if name := GetAlias(className); len(name) > 0 {
-        return App.GetContainer().Get(name, config, params...).Interface()
+        rv, e := App.GetContainer().GetE(name, config, params...)
+        if e != nil {
+            return nil, e
+        }
+
+        return rv.Interface(), nil
     }
 
-    panic("unknown class: " + className)
+    return nil, NewError(ErrNotFound, nil, "unknown class: %s", className)
 }
 
 // Configure configure object using the given configuration,
@@ -165,32 +222,120 @@ func Configure(obj interface{}, config map[string]interface{}) {
         panic("Configure: obj require a pointer or reflect.Value of a pointer")
     }
 
-    // rv refer to the value of pointer
-    rv := v.Elem()
+    // in strict mode, unknown keys and type errors panic with
+    // *ConfigError, key of nested object error is prefixed.
+    strict, calling := App.container != nil && App.container.strict, ""
+    if strict {
+        defer func() {
+            if v := recover(); v != nil {
+                if e, ok := v.(*ConfigError); ok && len(calling) > 0 {
+                    e.Key = calling + "." + e.Key
+                }
+
+                panic(v)
+            }
+        }()
+    }
 
     for key, val := range config {
         if key == "class" {
             continue
         }
 
-        // change key to title string
-        key = strings.Title(key)
+        method, field := configTarget(v, key)
 
         // check object's setter method
-        if method := v.MethodByName("Set" + key); method.IsValid() {
-            newVal := reflect.ValueOf(val).Convert(method.Type().In(0))
+        if method.IsValid() {
+            newVal := convertConfig(key, val, method.Type().In(0), strict)
+            calling = key
             method.Call([]reflect.Value{newVal})
+            calling = ""
             continue
         }
 
         // check object's public field
-        field := rv.FieldByName(key)
-        if field.IsValid() && field.CanSet() {
-            newVal := reflect.ValueOf(val).Convert(field.Type())
+        if field.IsValid() {
+            newVal := convertConfig(key, val, field.Type(), strict)
             field.Set(newVal)
             continue
         }
+
+        if strict {
+            panic(&ConfigError{Key: key, Err: fmt.Errorf("unknown key")})
+        }
+    }
+}
+
+// configTarget get setter method or settable public field of
+// key, key is matched case-insensitively if no exact match.
+func configTarget(v reflect.Value, key string) (method, field reflect.Value) {
+    // change key to title string
+    name := strings.Title(key)
+    if method = v.MethodByName("Set" + name); !method.IsValid() {
+        vt := v.Type()
+        for i := 0; i < vt.NumMethod(); i++ {
+            if strings.EqualFold(vt.Method(i).Name, "Set"+name) {
+                method = v.Method(i)
+                break
+            }
+        }
     }
+
+    if method.IsValid() {
+        if method.Type().NumIn() != 1 {
+            method = reflect.Value{}
+        }
+
+        return
+    }
+
+    rv := v.Elem()
+    if field = rv.FieldByName(name); !field.IsValid() {
+        field = rv.FieldByNameFunc(func(n string) bool { return strings.EqualFold(n, name) })
+    }
+
+    if field.IsValid() && !field.CanSet() {
+        field = reflect.Value{}
+    }
+
+    return
+}
+
+// convertConfig convert config value to type t, bool and
+// number value for string type is formatted as string.
+func convertConfig(key string, val interface{}, t reflect.Type, strict bool) reflect.Value {
+    if e := checkConfigType(val, t); e != nil {
+        if strict {
+            panic(&ConfigError{Key: key, Err: e})
+        }
+
+        panic(fmt.Sprintf("Configure: invalid config of %s, %s", key, e))
+    }
+
+    if t.Kind() == reflect.String && reflect.TypeOf(val).Kind() != reflect.String {
+        return reflect.ValueOf(Util.ToString(val)).Convert(t)
+    }
+
+    return reflect.ValueOf(val).Convert(t)
+}
+
+// checkConfigType check whether config value can be converted to type t
+func checkConfigType(val interface{}, t reflect.Type) error {
+    if val == nil {
+        return fmt.Errorf("expect %s, got nil", t)
+    }
+
+    vt := reflect.TypeOf(val)
+    if t.Kind() == reflect.String {
+        switch vt.Kind() {
+        case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+            return nil
+        }
+    } else if vt.ConvertibleTo(t) {
+        return nil
+    }
+
+    return fmt.Errorf("expect %s, got %T", t, val)
 }
 
 // ConstructAndInit construct and initialize object,
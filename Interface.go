@@ -1,6 +1,9 @@
 package pgo
 
-import "time"
+import (
+    "context"
+    "time"
+)
 
 type IBind interface {
     GetBindInfo(v interface{}) interface{}
@@ -39,6 +42,39 @@ type IConfigParser interface {
     Parse(path string) map[string]interface{}
 }
 
+type IConfigProvider interface {
+    Fetch(ctx context.Context, wait bool) (map[string]interface{}, error)
+}
+
+type IReloadable interface {
+    Reload(config map[string]interface{})
+}
+
+type IStarter interface {
+    Start() error
+}
+
+type IStopper interface {
+    Stop() error
+}
+
+type IHealthChecker interface {
+    HealthCheck() error
+}
+
+// ICache interface of cache clients, semantics of ttl methods:
+// Expire set expire of existing key, 0 means never expire.
+// TTL get remaining ttl of key, 0 means never expire, ok is false if key not exists.
+// Touch get value of key and set expire of key like Expire.
+// GetSet set value of key and return the old value.
+// CompareAndSwap set value of key if its current value equals to old.
+// expire of GetSet and CompareAndSwap is the default expire of client
+// if omitted, explicit 0 means never expire.
+// Incr increase value of key, key not exists is created without expire.
+// IncrWithExpire increase value of key, expire is set if key created.
+// counters of memcache are unsigned, negative delta never decreases value
+// of memcache below 0 and key not exists is created as 0, while memory and
+// redis allow negative value.
 type ICache interface {
     Get(key string) *Value
     MGet(keys []string) map[string]*Value
@@ -50,4 +86,18 @@ type ICache interface {
     MDel(keys []string) bool
     Exists(key string) bool
     Incr(key string, delta int) int
+    IncrWithExpire(key string, delta int, expire time.Duration) int
+    Expire(key string, expire time.Duration) bool
+    TTL(key string) (time.Duration, bool)
+    Touch(key string, expire time.Duration) *Value
+    GetSet(key string, value interface{}, expire ...time.Duration) *Value
+    CompareAndSwap(key string, old, new interface{}, expire ...time.Duration) bool
+}
+
+// ILockStore store of distributed lock, lock of key is held
+// by token, only the holder of token can renew or release it.
+type ILockStore interface {
+    AcquireLock(key, token string, ttl time.Duration) bool
+    RenewLock(key, token string, ttl time.Duration) bool
+    ReleaseLock(key, token string) bool
 }
@@ -0,0 +1,114 @@
+package pgo_test
+
+import (
+    "bufio"
+    "fmt"
+    "net"
+    "strconv"
+    "strings"
+    "sync"
+    "testing"
+
+    "github.com/pinguo/pgo"
+    "github.com/pinguo/pgo/Client/Memcache"
+    "github.com/pinguo/pgo/Client/Memory"
+)
+
+// serveMemcacheCounter serve incr, decr and add of memcache text
+// protocol, counters are unsigned and decr floors at 0 as memcached.
+func serveMemcacheCounter(t *testing.T) string {
+    ln, e := net.Listen("tcp", "127.0.0.1:0")
+    if e != nil {
+        t.Fatal(e)
+    }
+
+    t.Cleanup(func() { ln.Close() })
+
+    var lock sync.Mutex
+    counters := make(map[string]uint64)
+    go func() {
+        for {
+            nc, e := ln.Accept()
+            if e != nil {
+                return
+            }
+
+            go func() {
+                defer nc.Close()
+                rd := bufio.NewReader(nc)
+                for {
+                    line, e := rd.ReadString('\n')
+                    if e != nil {
+                        return
+                    }
+
+                    args := strings.Fields(line)
+                    lock.Lock()
+                    switch args[0] {
+                    case "incr", "decr":
+                        n, _ := strconv.ParseUint(args[2], 10, 64)
+                        if v, ok := counters[args[1]]; !ok {
+                            fmt.Fprint(nc, "NOT_FOUND\r\n")
+                        } else {
+                            if args[0] == "incr" {
+                                v += n
+                            } else if v > n {
+                                v -= n
+                            } else {
+                                v = 0
+                            }
+
+                            counters[args[1]] = v
+                            fmt.Fprintf(nc, "%d\r\n", v)
+                        }
+                    case "add":
+                        data, _ := rd.ReadString('\n')
+                        if _, ok := counters[args[1]]; ok {
+                            fmt.Fprint(nc, "NOT_STORED\r\n")
+                        } else {
+                            counters[args[1]], _ = strconv.ParseUint(strings.TrimSpace(data), 10, 64)
+                            fmt.Fprint(nc, "STORED\r\n")
+                        }
+                    default:
+                        fmt.Fprint(nc, "ERROR\r\n")
+                    }
+                    lock.Unlock()
+                }
+            }()
+        }
+    }()
+
+    return ln.Addr().String()
+}
+
+func TestICacheIncrNegative(t *testing.T) {
+    mc := &Memcache.Client{}
+    pgo.ConstructAndInit(mc, map[string]interface{}{"servers": []interface{}{serveMemcacheCounter(t)}})
+
+    mem := &Memory.Client{}
+    pgo.ConstructAndInit(mem, nil)
+
+    tests := []struct {
+        name  string
+        cache pgo.ICache
+        want  []int
+    }{
+        {"memory", mem, []int{-2, 1, -4}},
+        {"memcache", mc, []int{0, 3, 0}},
+    }
+
+    for _, tt := range tests {
+        got := []int{
+            tt.cache.IncrWithExpire("counter", -2, 0),
+            tt.cache.Incr("counter", 3),
+            tt.cache.Incr("counter", -5),
+        }
+
+        for i := range got {
+            if got[i] != tt.want[i] {
+                t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+                break
+            }
+        }
+    }
+}
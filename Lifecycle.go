@@ -0,0 +1,196 @@
+package pgo
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+// config key of component dependencies, eg.
+// components:
+//     cache:
+//         class: "@app/Lib/Cache"
+//         dependsOn: ["redis", "memory"]
+const componentDependsKey = "dependsOn"
+
+// Start load configured components in order of dependencies,
+// dependencies are declared by "dependsOn" of component config
+// and inject tags of component class, components implement
+// IStarter are started after loading, error is returned if
+// any component failed to load or start.
+func (app *Application) Start() error {
+    ids, e := app.componentOrder()
+    if e != nil {
+        return e
+    }
+
+    for _, id := range ids {
+        if e := app.startComponent(id); e != nil {
+            return e
+        }
+    }
+
+    app.events.Trigger(EventAppStart, nil)
+    return nil
+}
+
+// startComponent load and start component, panic is returned as error
+func (app *Application) startComponent(id string) (err error) {
+    defer func() {
+        if v := recover(); v != nil {
+            if e, ok := v.(error); ok {
+                err = fmt.Errorf("failed to load component %s, %w", id, e)
+            } else {
+                err = fmt.Errorf("failed to load component %s, %s", id, Util.ToString(v))
+            }
+        }
+    }()
+
+    app.Get(id)
+    return nil
+}
+
+// Stop stop loaded components implement IStopper in reverse
+// order of loading, errors are logged and returned.
+func (app *Application) Stop() []error {
+    app.events.Trigger(EventAppStop, nil)
+
+    app.lock.RLock()
+    order := append([]string{}, app.order...)
+    app.lock.RUnlock()
+
+    errs := make([]error, 0)
+    for i := len(order) - 1; i >= 0; i-- {
+        if stopper, ok := app.Get(order[i]).(IStopper); ok {
+            if e := app.stopComponent(stopper); e != nil {
+                e = fmt.Errorf("failed to stop component %s, %s", order[i], e)
+                GLogger().Error("%s", e)
+                errs = append(errs, e)
+            }
+        }
+    }
+
+    return errs
+}
+
+// stopComponent stop component, panic is returned as error
+func (app *Application) stopComponent(stopper IStopper) (err error) {
+    defer func() {
+        if v := recover(); v != nil {
+            err = fmt.Errorf("%s", Util.ToString(v))
+        }
+    }()
+
+    return stopper.Stop()
+}
+
+// HealthCheck check health of loaded components implement
+// IHealthChecker, error of unhealthy component is returned.
+func (app *Application) HealthCheck() map[string]error {
+    app.lock.RLock()
+    checkers := make(map[string]IHealthChecker)
+    for id, component := range app.components {
+        if checker, ok := component.(IHealthChecker); ok {
+            checkers[id] = checker
+        }
+    }
+    app.lock.RUnlock()
+
+    errs := make(map[string]error)
+    for id, checker := range checkers {
+        if e := checker.HealthCheck(); e != nil {
+            errs[id] = e
+        }
+    }
+
+    return errs
+}
+
+// componentOrder get id of configured components sorted
+// by dependencies, dependencies come first.
+func (app *Application) componentOrder() ([]string, error) {
+    components, _ := app.config.Get("app.components").(map[string]interface{})
+    order := make([]string, 0, len(components))
+    state := make(map[string]int) // 1: visiting, 2: visited
+
+    var visit func(id string, path []string) error
+    visit = func(id string, path []string) error {
+        switch state[id] {
+        case 1:
+            return circularError(append(path, id))
+        case 2:
+            return nil
+        }
+
+        state[id] = 1
+        for _, dep := range app.componentDepends(id) {
+            if _, ok := components[dep]; !ok {
+                return fmt.Errorf("component %s depends on unknown component %s", id, dep)
+            }
+
+            if e := visit(dep, append(path, id)); e != nil {
+                return e
+            }
+        }
+
+        state[id] = 2
+        order = append(order, id)
+        return nil
+    }
+
+    core := app.coreComponents()
+    for _, id := range sortedKeys(components) {
+        // skip core component whose class is not bound
+        if _, ok := core[id]; ok && !app.container.Has(GetAlias(app.config.GetString("app.components."+id+".class", ""))) {
+            continue
+        }
+
+        if e := visit(id, nil); e != nil {
+            return nil, e
+        }
+    }
+
+    return order, nil
+}
+
+// componentDepends get dependencies of component
+func (app *Application) componentDepends(id string) []string {
+    key := "app.components." + id
+    deps := app.config.GetSliceString(key + "." + componentDependsKey)
+
+    // components injected to fields of class
+    name := GetAlias(app.config.GetString(key+".class", ""))
+    if item, ok := app.container.items[name]; ok {
+        for _, f := range item.injects {
+            if !f.class {
+                deps = append(deps, f.name)
+            }
+        }
+    }
+
+    return deps
+}
+
+// dependsCycle get path of circular dependencies reachable
+// from component, nil if dependencies are not circular.
+func (app *Application) dependsCycle(id string, path []string) []string {
+    for i, v := range path {
+        if v == id {
+            return append(path[i:len(path):len(path)], id)
+        }
+    }
+
+    path = append(path[:len(path):len(path)], id)
+    for _, dep := range app.componentDepends(id) {
+        if cycle := app.dependsCycle(dep, path); cycle != nil {
+            return cycle
+        }
+    }
+
+    return nil
+}
+
+func circularError(path []string) error {
+    return fmt.Errorf("circular dependency of components: %s", strings.Join(path, " -> "))
+}
@@ -0,0 +1,24 @@
+package pgo
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestLoadComponentCircular(t *testing.T) {
+    App.GetConfig().Set("app.components.cycleA", map[string]interface{}{
+        "class": "@pgo/ErrorHandler", "dependsOn": []interface{}{"cycleB"},
+    })
+    App.GetConfig().Set("app.components.cycleB", map[string]interface{}{
+        "class": "@pgo/ErrorHandler", "dependsOn": []interface{}{"cycleA"},
+    })
+
+    defer func() {
+        v := recover()
+        if msg, _ := v.(string); !strings.Contains(msg, "circular dependency of components: cycleA -> cycleB -> cycleA") {
+            t.Errorf("expect circular dependency panic, got %v", v)
+        }
+    }()
+
+    App.Get("cycleA")
+}
@@ -0,0 +1,297 @@
+package pgo
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
+    mrand "math/rand"
+    "sync"
+    "time"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+const (
+    defaultLockTtl    = 30 * time.Second
+    defaultMinBackoff = 10 * time.Millisecond
+    defaultMaxBackoff = time.Second
+)
+
+// Lock distributed lock of key on lock store(eg. Redis or Memcache
+// client), lock is acquired with a random token and expires in ttl,
+// only the holder of token can refresh or release the lock. if the
+// watchdog is enabled(default), lock is refreshed every ttl/3 in
+// background until released, Done is closed when lock is released
+// or lost. lock is not reentrant, usage:
+//     lock := redis.NewLock("job:daily", 30*time.Second)
+//     if lock.TryLock() {
+//         defer lock.Unlock()
+//         // do something exclusively
+//     }
+type Lock struct {
+    store      ILockStore
+    key        string
+    ttl        time.Duration
+    watchdog   bool
+    minBackoff time.Duration
+    maxBackoff time.Duration
+
+    lock  sync.Mutex
+    held  bool
+    token string
+    done  chan struct{}
+}
+
+// NewLock create lock of key on store, ttl is expire of lock, 0 for 30s
+func NewLock(store ILockStore, key string, ttl time.Duration) *Lock {
+    if ttl <= 0 {
+        ttl = defaultLockTtl
+    }
+
+    return &Lock{
+        store:      store,
+        key:        key,
+        ttl:        ttl,
+        watchdog:   true,
+        minBackoff: defaultMinBackoff,
+        maxBackoff: defaultMaxBackoff,
+    }
+}
+
+// SetWatchdog set whether to refresh lock in background, default true
+func (l *Lock) SetWatchdog(v bool) {
+    l.watchdog = v
+}
+
+// SetBackoff set min and max wait between attempts of
+// blocking Lock, default 10ms and 1s, wait is doubled
+// after each attempt and a random jitter is applied.
+func (l *Lock) SetBackoff(min, max time.Duration) {
+    if min <= 0 || max < min {
+        panic("lock: invalid backoff, min must be positive and not greater than max")
+    }
+
+    l.minBackoff, l.maxBackoff = min, max
+}
+
+// GetKey get key of lock
+func (l *Lock) GetKey() string {
+    return l.key
+}
+
+// GetToken get token of current hold, empty if not held
+func (l *Lock) GetToken() string {
+    l.lock.Lock()
+    defer l.lock.Unlock()
+
+    if !l.held {
+        return ""
+    }
+
+    return l.token
+}
+
+// Held check whether lock is held, lock lost
+// by failed refresh is not held any more.
+func (l *Lock) Held() bool {
+    l.lock.Lock()
+    defer l.lock.Unlock()
+
+    return l.held
+}
+
+// Done get channel closed when current hold is released
+// or lost, nil is returned if lock is never acquired.
+func (l *Lock) Done() <-chan struct{} {
+    l.lock.Lock()
+    defer l.lock.Unlock()
+
+    return l.done
+}
+
+// TryLock try to acquire lock without blocking,
+// false if lock is held by others or by itself.
+func (l *Lock) TryLock() bool {
+    l.lock.Lock()
+    defer l.lock.Unlock()
+
+    if l.held {
+        return false
+    }
+
+    token := newLockToken()
+    if !l.store.AcquireLock(l.key, token, l.ttl) {
+        return false
+    }
+
+    l.held, l.token, l.done = true, token, make(chan struct{})
+    if l.watchdog {
+        go l.watch(token, l.done)
+    }
+
+    return true
+}
+
+// Lock acquire lock, blocking with backoff until acquired
+// or ctx is done, error of ctx is returned if not acquired,
+// failures of store are logged and retried like conflicts.
+func (l *Lock) Lock(ctx context.Context) error {
+    if e := ctx.Err(); e != nil {
+        return e
+    }
+
+    backoff := l.minBackoff
+    for {
+        if locked, e := l.tryLock(); locked {
+            return nil
+        } else if e != nil {
+            GLogger().Warn("lock: failed to acquire lock %s, %s", l.key, e)
+        }
+
+        // wait a random duration in [backoff/2, backoff]
+        wait := backoff/2 + time.Duration(mrand.Int63n(int64(backoff/2)+1))
+        timer := time.NewTimer(wait)
+        select {
+        case <-ctx.Done():
+            timer.Stop()
+            return ctx.Err()
+        case <-timer.C:
+        }
+
+        if backoff *= 2; backoff > l.maxBackoff {
+            backoff = l.maxBackoff
+        }
+    }
+}
+
+// Unlock release lock, false if lock is not held
+// or the lock in store is not held by token any more.
+func (l *Lock) Unlock() bool {
+    l.lock.Lock()
+    if !l.held {
+        l.lock.Unlock()
+        return false
+    }
+
+    token := l.token
+    l.held = false
+    close(l.done)
+    l.lock.Unlock()
+
+    return l.store.ReleaseLock(l.key, token)
+}
+
+// Refresh reset expire of lock to ttl, false if lock is
+// not held, lock is lost if it's held by others in store.
+func (l *Lock) Refresh() bool {
+    l.lock.Lock()
+    token, held := l.token, l.held
+    l.lock.Unlock()
+
+    if !held {
+        return false
+    } else if l.store.RenewLock(l.key, token, l.ttl) {
+        return true
+    }
+
+    l.lose(token)
+    return false
+}
+
+// Run call fn if lock is acquired and release lock after fn
+// returns, false if lock is held by others, eg. run a cron
+// job of command controller on one of multiple hosts:
+//     func (c *JobCommand) ActionDaily() {
+//         redis := c.GetObject(Redis.AdapterClass).(*Redis.Adapter)
+//         redis.NewLock("job:daily", time.Minute).Run(func() {
+//             // do daily job
+//         })
+//     }
+func (l *Lock) Run(fn func()) bool {
+    if !l.TryLock() {
+        return false
+    }
+
+    defer l.Unlock()
+    fn()
+    return true
+}
+
+// watch refresh lock every ttl/3 until done, failures of
+// store are retried until ttl elapsed since last refresh.
+func (l *Lock) watch(token string, done chan struct{}) {
+    interval, last := l.ttl/3, time.Now()
+    for {
+        select {
+        case <-done:
+            return
+        case <-time.After(interval):
+        }
+
+        renewed, e := l.renew(token)
+        if e != nil && time.Since(last) < l.ttl {
+            GLogger().Warn("lock: failed to refresh lock %s, %s", l.key, e)
+            continue
+        } else if renewed {
+            last = time.Now()
+            continue
+        }
+
+        GLogger().Warn("lock: lock %s is lost", l.key)
+        l.lose(token)
+        return
+    }
+}
+
+// tryLock acquire lock like TryLock, panic of store is returned as error
+func (l *Lock) tryLock() (locked bool, err error) {
+    defer func() {
+        if v := recover(); v != nil {
+            err = lockError(v)
+        }
+    }()
+
+    return l.TryLock(), nil
+}
+
+// renew lock by store, panic of store is returned as error
+func (l *Lock) renew(token string) (renewed bool, err error) {
+    defer func() {
+        if v := recover(); v != nil {
+            err = lockError(v)
+        }
+    }()
+
+    return l.store.RenewLock(l.key, token, l.ttl), nil
+}
+
+// lose mark hold of token as lost
+func (l *Lock) lose(token string) {
+    l.lock.Lock()
+    defer l.lock.Unlock()
+
+    if l.held && l.token == token {
+        l.held = false
+        close(l.done)
+    }
+}
+
+// lockError convert recovered panic of store to error
+func lockError(v interface{}) error {
+    if e, ok := v.(error); ok {
+        return e
+    }
+
+    return errors.New(Util.ToString(v))
+}
+
+// newLockToken generate random token of lock
+func newLockToken() string {
+    b := make([]byte, 16)
+    if _, e := rand.Read(b); e != nil {
+        return Util.GenUniqueId()
+    }
+
+    return hex.EncodeToString(b)
+}
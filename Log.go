@@ -2,12 +2,16 @@ package pgo
 
 import (
     "bytes"
+    "compress/gzip"
     "fmt"
+    "io"
     "os"
     "path/filepath"
     "runtime"
+    "sort"
     "strings"
     "sync"
+    "sync/atomic"
     "time"
 
     "github.com/pinguo/pgo/Util"
@@ -26,6 +30,14 @@ const (
     rotateNone   = 0
     rotateHourly = 1
     rotateDaily  = 2
+
+    overflowBlock      = 0
+    overflowDropNewest = 1
+    overflowDropOldest = 2
+    overflowSample     = 3
+
+    // levels that will never be dropped on overflow
+    levelNeverDrop = LevelError | LevelFatal
 )
 
 // LevelToString convert int level to string
@@ -98,14 +110,58 @@ type LogItem struct {
     LogId   string
     Trace   string
     Message string
+
+    escalated bool // only enabled by request escalation
+}
+
+// logWorker process log items of a target in its
+// own goroutine, so slow target won't block others.
+type logWorker struct {
+    name    string
+    target  ITarget
+    msgChan chan *LogItem
+    dropped uint64 // num of items dropped on overflow
+    counter uint64 // num of items overflowed, for sampling
+
+    neverDrop int64 // num of items can not be dropped in channel
 }
 
-// Log the log component, configuration:
+// Log the log component, each target has a worker goroutine and
+// a channel of chanLen, if the channel is full, the overflow policy
+// determines how new log item is handled:
+// block: wait until channel is available
+// dropNewest: drop the new item
+// dropOldest: drop the oldest item in channel, or the new item if
+//     any ERROR or FATAL item is in channel to keep the order
+// sample: wait for one of every sampleRate items, drop others
+// ERROR and FATAL items are never dropped.
+// messages can be rate limited or sampled per level, messages are
+// grouped by format string or call site(limitBy), and num of suppressed
+// messages is logged every summaryInterval.
+// log level of a request can be escalated by debug header or query with
+// token matches debugSecret, eg. "X-Debug-Level: debug" and "X-Debug-Level-Token: secret",
+// log lines of escalated request can be returned in response header(debugOutput),
+// and messages only enabled by escalation can be sent to debugTarget.
+// levels and traceLevels are reloaded on config change, configuration:
 // log:
 //     levels: "ALL"
 //     traceLevels: "DEBUG"
 //     chanLen: 1000
 //     flushInterval: "60s"
+//     overflow: "block"
+//     sampleRate: 10
+//     rateLimit:
+//         WARN: 100
+//     rateInterval: "1s"
+//     sampling:
+//         DEBUG: 0.01
+//     limitBy: "message"
+//     summaryInterval: "60s"
+//     debugSecret: ""
+//     debugHeader: "X-Debug-Level"
+//     debugQuery: "_debugLevel"
+//     debugOutput: false
+//     debugTarget: ""
 //     targets:
 //         info:
 //             class: "@pgo/FileTarget"
@@ -120,13 +176,26 @@ type LogItem struct {
 //             maxLogFile: 10
 //             rotate: "daily"
 type Log struct {
-    levels        int
+    levels        int32
     chanLen       int
-    traceLevels   int
+    traceLevels   int32
     flushInterval time.Duration
+    overflow      int
+    sampleRate    int
     targets       map[string]ITarget
-    msgChan       chan *LogItem
+    workers       []*logWorker
     wg            sync.WaitGroup
+
+    limiter         logLimiter
+    summaryInterval time.Duration
+    summaryDone     chan struct{}
+    summaryWg       sync.WaitGroup
+
+    debugSecret string
+    debugHeader string
+    debugQuery  string
+    debugOutput bool
+    debugTarget string
 }
 
 func (d *Log) Construct() {
@@ -134,28 +203,51 @@ func (d *Log) Construct() {
     d.chanLen = 1000
     d.traceLevels = LevelDebug
     d.flushInterval = 60 * time.Second
+    d.overflow = overflowBlock
+    d.sampleRate = 10
+    d.limiter.limits = make(map[int]int)
+    d.limiter.samples = make(map[int]float64)
+    d.limiter.buckets = make(map[string]*limitBucket)
+    d.limiter.interval = time.Second
+    d.summaryInterval = 60 * time.Second
+    d.debugHeader = "X-Debug-Level"
+    d.debugQuery = "_debugLevel"
 }
 
 func (d *Log) Init() {
-    d.msgChan = make(chan *LogItem, d.chanLen)
-
     if len(d.targets) == 0 {
         // use console target as default
         d.targets = make(map[string]ITarget)
         d.targets["console"] = CreateObject("@pgo/ConsoleTarget").(ITarget)
     }
 
-    // start loop
-    d.wg.Add(1)
-    go d.loop()
+    // start a worker for each target
+    for name, target := range d.targets {
+        worker := &logWorker{
+            name:    name,
+            target:  target,
+            msgChan: make(chan *LogItem, d.chanLen),
+        }
+
+        d.workers = append(d.workers, worker)
+        d.wg.Add(1)
+        go d.loop(worker)
+    }
+
+    // start summary loop of limiter
+    if d.limiter.enabled() {
+        d.summaryDone = make(chan struct{})
+        d.summaryWg.Add(1)
+        go d.summaryLoop()
+    }
 }
 
 // SetLevels set levels to handle, default "ALL"
 func (d *Log) SetLevels(v interface{}) {
     if _, ok := v.(string); ok {
-        d.levels = parseLevels(v.(string))
+        d.levels = int32(parseLevels(v.(string)))
     } else if _, ok := v.(int); ok {
-        d.levels = v.(int)
+        d.levels = int32(v.(int))
     } else {
         panic(fmt.Sprintf("Log: invalid levels: %v", v))
     }
@@ -169,9 +261,9 @@ func (d *Log) SetChanLen(len int) {
 // SetTraceLevels set levels to trace, default "DEBUG"
 func (d *Log) SetTraceLevels(v interface{}) {
     if _, ok := v.(string); ok {
-        d.traceLevels = parseLevels(v.(string))
+        d.traceLevels = int32(parseLevels(v.(string)))
     } else if _, ok := v.(int); ok {
-        d.traceLevels = v.(int)
+        d.traceLevels = int32(v.(int))
     } else {
         panic(fmt.Sprintf("Log: invalid trace levels: %v", v))
     }
@@ -186,6 +278,29 @@ func (d *Log) SetFlushInterval(v string) {
     }
 }
 
+// SetOverflow set overflow policy(block, dropNewest, dropOldest, sample), default "block"
+func (d *Log) SetOverflow(overflow string) {
+    switch strings.ToUpper(overflow) {
+    case "BLOCK":
+        d.overflow = overflowBlock
+    case "DROPNEWEST":
+        d.overflow = overflowDropNewest
+    case "DROPOLDEST":
+        d.overflow = overflowDropOldest
+    case "SAMPLE":
+        d.overflow = overflowSample
+    default:
+        panic("Log: invalid overflow: " + overflow)
+    }
+}
+
+// SetSampleRate set sample rate of sample policy, default 10
+func (d *Log) SetSampleRate(sampleRate int) {
+    if sampleRate > 0 {
+        d.sampleRate = sampleRate
+    }
+}
+
 // SetTargets set output target, ConsoleTarget will be used if no targets specified
 func (d *Log) SetTargets(targets map[string]interface{}) {
     d.targets = make(map[string]ITarget)
@@ -201,9 +316,25 @@ func (d *Log) SetTargets(targets map[string]interface{}) {
     }
 }
 
+// Reload update levels and traceLevels on config change,
+// other properties are not reloadable.
+func (d *Log) Reload(config map[string]interface{}) {
+    l := &Log{levels: LevelAll, traceLevels: LevelDebug}
+    if v, ok := config["levels"]; ok {
+        l.SetLevels(v)
+    }
+
+    if v, ok := config["traceLevels"]; ok {
+        l.SetTraceLevels(v)
+    }
+
+    atomic.StoreInt32(&d.levels, l.levels)
+    atomic.StoreInt32(&d.traceLevels, l.traceLevels)
+}
+
 // GetLogger get a new logger with name and id specified
 func (d *Log) GetLogger(name, logId string) *Logger {
-    return &Logger{name, logId, d}
+    return &Logger{name: name, logId: logId, log: d}
 }
 
 // GetProfiler get a new profiler
@@ -211,18 +342,39 @@ func (d *Log) GetProfiler() *Profiler {
     return &Profiler{}
 }
 
+// GetDropped get num of items dropped on overflow,
+// if no target specified, total of all targets returned.
+func (d *Log) GetDropped(target ...string) uint64 {
+    total := uint64(0)
+    for _, worker := range d.workers {
+        if len(target) == 0 || worker.name == target[0] {
+            total += atomic.LoadUint64(&worker.dropped)
+        }
+    }
+
+    return total
+}
+
 // Flush close msg chan and wait loop end
 func (d *Log) Flush() {
-    close(d.msgChan)
+    if d.summaryDone != nil {
+        close(d.summaryDone)
+        d.summaryWg.Wait()
+    }
+
+    for _, worker := range d.workers {
+        close(worker.msgChan)
+    }
+
     d.wg.Wait()
 }
 
 func (d *Log) isHandling(level int) bool {
-    return level&d.levels != 0
+    return level&int(atomic.LoadInt32(&d.levels)) != 0
 }
 
 func (d *Log) addItem(item *LogItem) {
-    if d.traceLevels&item.Level != 0 {
+    if int(atomic.LoadInt32(&d.traceLevels))&item.Level != 0 {
         if _, file, line, ok := runtime.Caller(3); ok {
             if pos := strings.LastIndex(file, "src/"); pos > 0 {
                 file = file[pos+4:]
@@ -232,35 +384,112 @@ func (d *Log) addItem(item *LogItem) {
         }
     }
 
-    d.msgChan <- item
+    d.dispatch(item)
 }
 
-func (d *Log) loop() {
-    flushTimer := time.Tick(d.flushInterval)
+// dispatch item to workers of targets
+func (d *Log) dispatch(item *LogItem) {
+    for _, worker := range d.workers {
+        // send escalated item to debug target only
+        if item.escalated && d.debugTarget != "" {
+            if worker.name == d.debugTarget {
+                d.send(worker, item)
+            }
+            continue
+        }
+
+        // skip target not handling this level
+        if t, ok := worker.target.(interface{ IsHandling(int) bool }); ok && !t.IsHandling(item.Level) {
+            continue
+        }
 
-    for {
-        select {
-        case item, ok := <-d.msgChan:
-            for _, target := range d.targets {
-                if ok {
-                    target.Process(item)
-                } else {
-                    target.Flush(true)
-                }
+        d.send(worker, item)
+    }
+}
+
+// send item to worker channel, apply overflow policy if channel is full
+func (d *Log) send(worker *logWorker, item *LogItem) {
+    if item.Level&levelNeverDrop != 0 {
+        atomic.AddInt64(&worker.neverDrop, 1)
+        worker.msgChan <- item
+        return
+    } else if d.overflow == overflowBlock {
+        worker.msgChan <- item
+        return
+    }
+
+    select {
+    case worker.msgChan <- item:
+        return
+    default:
+    }
+
+    switch d.overflow {
+    case overflowDropOldest:
+        if d.dropOldest(worker) {
+            select {
+            case worker.msgChan <- item:
+                return
+            default:
             }
+        }
+
+    case overflowSample:
+        if atomic.AddUint64(&worker.counter, 1)%uint64(d.sampleRate) == 0 {
+            worker.msgChan <- item
+            return
+        }
+    }
+
+    atomic.AddUint64(&worker.dropped, 1)
+}
+
+// dropOldest drop the oldest item in channel, false if nothing
+// dropped, items can not be dropped are kept in order, so nothing
+// is dropped if any of them is in channel.
+func (d *Log) dropOldest(worker *logWorker) bool {
+    if atomic.LoadInt64(&worker.neverDrop) > 0 {
+        return false
+    }
+
+    select {
+    case old := <-worker.msgChan:
+        if old.Level&levelNeverDrop == 0 {
+            atomic.AddUint64(&worker.dropped, 1)
+            return true
+        }
 
+        // item can not be dropped is queued after the check,
+        // send it back like the item is sent by its caller.
+        worker.msgChan <- old
+    default:
+    }
+
+    return false
+}
+
+func (d *Log) loop(worker *logWorker) {
+    flushTimer := time.NewTicker(d.flushInterval)
+    defer flushTimer.Stop()
+    defer d.wg.Done()
+
+    for {
+        select {
+        case item, ok := <-worker.msgChan:
             if !ok {
-                goto end
+                worker.target.Flush(true)
+                return
             }
-        case <-flushTimer:
-            for _, target := range d.targets {
-                target.Flush(false)
+
+            if item.Level&levelNeverDrop != 0 {
+                atomic.AddInt64(&worker.neverDrop, -1)
             }
+
+            worker.target.Process(item)
+        case <-flushTimer.C:
+            worker.target.Flush(false)
         }
     }
-
-end:
-    d.wg.Done()
 }
 
 // Logger
@@ -268,14 +497,28 @@ type Logger struct {
     name  string
     logId string
     log   *Log
+
+    levels     int      // levels of escalated request
+    debugLines []string // log lines of escalated request
 }
 
 func (l *Logger) init(name, logId string, log *Log) {
     l.name, l.logId, l.log = name, logId, log
+    l.levels, l.debugLines = 0, nil
 }
 
 func (l *Logger) logMsg(level int, format string, v ...interface{}) {
+    escalated := false
     if !l.log.isHandling(level) {
+        if level&l.levels == 0 {
+            return
+        }
+
+        escalated = true
+    }
+
+    // rate limit and sampling, escalated request is not limited
+    if l.levels == 0 && !l.log.limiter.allow(level, format, 2) {
         return
     }
 
@@ -292,7 +535,14 @@ func (l *Logger) logMsg(level int, format string, v ...interface{}) {
         item.Message = fmt.Sprintf(format, v...)
     }
 
+    // mask secrets of config
+    item.Message = App.GetConfig().Mask(item.Message)
+    item.escalated = escalated
     l.log.addItem(item)
+
+    if l.levels != 0 && l.log.debugOutput {
+        l.collect(item)
+    }
 }
 
 func (l *Logger) Debug(format string, v ...interface{}) {
@@ -507,26 +757,42 @@ func (c *ConsoleTarget) Flush(final bool) {
     os.Stdout.Sync()
 }
 
-// FileTarget target for file, configuration:
+// FileTarget target for file, the file handle is kept open and
+// reopened on SIGUSR1, so logrotate's create and copytruncate mode
+// both work. log file is rotated by time(rotate) or by size(maxFileSize),
+// rotated file can be compressed with gzip, backups exceed maxLogFile,
+// maxLogAge or maxTotalSize will be removed, configuration:
 // info:
 //     class: "@pgo/FileTarget"
 //     levels: "DEBUG,INFO,NOTICE"
 //     filePath: "@runtime/info.log"
 //     maxLogFile: 10
+//     maxLogAge: "240h"
+//     maxFileSize: "1GB"
+//     maxTotalSize: "10GB"
 //     maxBufferByte: 10485760
 //     maxBufferLine: 10000
 //     rotate: "daily"
+//     compress: true
 type FileTarget struct {
     Target
     filePath      string
     maxLogFile    int
+    maxLogAge     time.Duration
+    maxFileSize   int64
+    maxTotalSize  int64
     maxBufferByte int
     maxBufferLine int
     rotate        int
+    compress      bool
 
+    file          *os.File
+    fileSize      int64
+    reopen        int32
     buffer        bytes.Buffer
     lastRotate    time.Time
     curBufferLine int
+    cleanLock     sync.Mutex
 }
 
 func (f *FileTarget) Construct() {
@@ -540,21 +806,13 @@ func (f *FileTarget) Construct() {
 
 func (f *FileTarget) Init() {
     f.filePath = GetAlias(f.filePath)
-    h, e := os.OpenFile(f.filePath, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
-    if e != nil {
-        panic(fmt.Sprintf("FileTarget: failed to open file: %s, e: %s", f.filePath, e))
-    }
-
-    defer h.Close()
-
-    stat, e := h.Stat()
-    if e != nil {
-        panic(fmt.Sprintf("FileTarget: failed to stat file: %s, e: %s", f.filePath, e))
-    }
+    f.openFile()
 
     f.curBufferLine = 0
-    f.lastRotate = stat.ModTime()
     f.buffer.Grow(f.maxBufferByte)
+
+    // reopen log file on SIGUSR1
+    notifyReopen(f)
 }
 
 // SetFilePath set file path, default "@runtime/app.log"
@@ -567,6 +825,25 @@ func (f *FileTarget) SetMaxLogFile(maxLogFile int) {
     f.maxLogFile = maxLogFile
 }
 
+// SetMaxLogAge set max age of log backups, default 0(no limit)
+func (f *FileTarget) SetMaxLogAge(v string) {
+    if maxLogAge, e := time.ParseDuration(v); e != nil {
+        panic(fmt.Sprintf("FileTarget: parse maxLogAge error, val:%s, err:%s", v, e.Error()))
+    } else {
+        f.maxLogAge = maxLogAge
+    }
+}
+
+// SetMaxFileSize set max size of log file, eg. "500MB", default 0(no limit)
+func (f *FileTarget) SetMaxFileSize(v interface{}) {
+    f.maxFileSize = f.parseSize("maxFileSize", v)
+}
+
+// SetMaxTotalSize set max total size of log backups, eg. "10GB", default 0(no limit)
+func (f *FileTarget) SetMaxTotalSize(v interface{}) {
+    f.maxTotalSize = f.parseSize("maxTotalSize", v)
+}
+
 // SetMaxBufferByte set max buffer bytes, default 10MB
 func (f *FileTarget) SetMaxBufferByte(maxBufferByte int) {
     f.maxBufferByte = maxBufferByte
@@ -591,6 +868,16 @@ func (f *FileTarget) SetRotate(rotate string) {
     }
 }
 
+// SetCompress set whether to compress rotated file with gzip, default false
+func (f *FileTarget) SetCompress(compress bool) {
+    f.compress = compress
+}
+
+// Reopen mark log file to be reopened before next write
+func (f *FileTarget) Reopen() {
+    atomic.StoreInt32(&f.reopen, 1)
+}
+
 // Process check and rotate log file if rotate is enable,
 // write log to buffer, flush buffer to file if buffer is full.
 func (f *FileTarget) Process(item *LogItem) {
@@ -598,13 +885,17 @@ func (f *FileTarget) Process(item *LogItem) {
         return
     }
 
-    // rotate log file
-    if f.shouldRotate(item.When) {
+    line := f.Format(item)
+
+    // rotate log file by time or by size
+    if f.shouldRotate(item.When) || f.exceedSize(len(line)) {
         f.rotateLog(item.When)
+    } else if atomic.LoadInt32(&f.reopen) == 1 {
+        f.Flush(false)
     }
 
     // write log to buffer
-    f.buffer.WriteString(f.Format(item))
+    f.buffer.WriteString(line)
     f.curBufferLine++
 
     // flush buffer to file
@@ -613,25 +904,58 @@ func (f *FileTarget) Process(item *LogItem) {
     }
 }
 
-// Flush flush log buffer to file
+// Flush flush log buffer to file, reopen file if needed
 func (f *FileTarget) Flush(final bool) {
-    // nothing to flush
-    if f.curBufferLine == 0 {
-        return
+    if f.curBufferLine > 0 {
+        // write log buffer to file
+        n, _ := f.buffer.WriteTo(f.file)
+        f.fileSize += n
+        f.buffer.Reset()
+        f.curBufferLine = 0
+    }
+
+    if atomic.CompareAndSwapInt32(&f.reopen, 1, 0) {
+        f.openFile()
     }
 
-    // open log file to write
-    h, e := os.OpenFile(f.filePath, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0644)
+    if final {
+        f.file.Sync()
+    }
+}
+
+func (f *FileTarget) parseSize(name string, v interface{}) int64 {
+    if s, ok := v.(string); ok {
+        size, e := Util.ParseSize(s)
+        if e != nil {
+            panic(fmt.Sprintf("FileTarget: parse %s error, val:%s, err:%s", name, s, e.Error()))
+        }
+        return size
+    }
+
+    return int64(Util.ToInt(v))
+}
+
+// open or reopen log file
+func (f *FileTarget) openFile() {
+    h, e := os.OpenFile(f.filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
     if e != nil {
         panic(fmt.Sprintf("FileTarget: failed to open file: %s, e: %s", f.filePath, e))
     }
 
-    defer h.Close()
+    stat, e := h.Stat()
+    if e != nil {
+        h.Close()
+        panic(fmt.Sprintf("FileTarget: failed to stat file: %s, e: %s", f.filePath, e))
+    }
+
+    if f.file != nil {
+        f.file.Close()
+    } else {
+        f.lastRotate = stat.ModTime()
+    }
 
-    // write log buffer to file
-    f.buffer.WriteTo(h)
-    f.buffer.Reset()
-    f.curBufferLine = 0
+    f.file = h
+    f.fileSize = stat.Size()
 }
 
 func (f *FileTarget) shouldRotate(now time.Time) bool {
@@ -644,38 +968,123 @@ func (f *FileTarget) shouldRotate(now time.Time) bool {
     return false
 }
 
-func (f *FileTarget) rotateLog(now time.Time) {
-    layout, interval := "", time.Duration(0)
-    if f.rotate == rotateHourly {
-        layout = "2006010215"
-        interval = time.Hour
-    } else if f.rotate == rotateDaily {
-        layout = "20060102"
-        interval = time.Hour * 24
-    } else {
-        return
+func (f *FileTarget) exceedSize(n int) bool {
+    if f.maxFileSize <= 0 {
+        return false
     }
 
-    // flush and close file
-    f.Flush(true)
+    size := f.fileSize + int64(f.buffer.Len())
+    return size > 0 && size+int64(n) > f.maxFileSize
+}
+
+func (f *FileTarget) rotateLog(now time.Time) {
+    // flush buffer to current file
+    f.Flush(false)
 
     // move current file to backup file
-    suffix := f.lastRotate.Format(layout)
-    newPath := fmt.Sprintf("%s.%s", f.filePath, suffix)
-    os.Rename(f.filePath, newPath)
+    backup := f.backupPath(now)
+    os.Rename(f.filePath, backup)
 
     // update last rotate time
     f.lastRotate = now
+    f.openFile()
 
-    // clean backup file
-    backups, _ := filepath.Glob(f.filePath + ".*")
-    if len(backups) > 0 {
-        for _, backup := range backups {
-            ext := filepath.Ext(backup)
-            d, e := time.ParseInLocation(layout, ext[1:], now.Location())
-            if e == nil && int(now.Sub(d)/interval) > f.maxLogFile {
-                os.Remove(backup)
-            }
+    // compress and clean backups in background
+    go f.cleanBackups(backup)
+}
+
+// get backup path, backup path of time based rotation is suffixed
+// by the time of last rotation, sequence num is appended if exists.
+func (f *FileTarget) backupPath(now time.Time) string {
+    var suffix string
+    switch f.rotate {
+    case rotateHourly:
+        suffix = f.lastRotate.Format("2006010215")
+    case rotateDaily:
+        suffix = f.lastRotate.Format("20060102")
+    default:
+        suffix = now.Format("20060102150405")
+    }
+
+    backup := fmt.Sprintf("%s.%s", f.filePath, suffix)
+    for i := 1; f.fileExists(backup) || f.fileExists(backup+".gz"); i++ {
+        backup = fmt.Sprintf("%s.%s.%d", f.filePath, suffix, i)
+    }
+
+    return backup
+}
+
+func (f *FileTarget) fileExists(path string) bool {
+    _, e := os.Stat(path)
+    return e == nil
+}
+
+func (f *FileTarget) cleanBackups(backup string) {
+    f.cleanLock.Lock()
+    defer f.cleanLock.Unlock()
+
+    defer func() {
+        if v := recover(); v != nil {
+            os.Stderr.WriteString(fmt.Sprintf("FileTarget: clean backups error, %v\n", v))
+        }
+    }()
+
+    if f.compress {
+        f.compressFile(backup)
+    }
+
+    matches, _ := filepath.Glob(f.filePath + ".*")
+    backups := make([]os.FileInfo, 0, len(matches))
+    paths := make(map[os.FileInfo]string, len(matches))
+    for _, path := range matches {
+        if info, e := os.Stat(path); e == nil && info.Mode().IsRegular() {
+            backups = append(backups, info)
+            paths[info] = path
+        }
+    }
+
+    // newest backup first
+    sort.Slice(backups, func(i, j int) bool {
+        return backups[i].ModTime().After(backups[j].ModTime())
+    })
+
+    totalSize, now := int64(0), time.Now()
+    for i, info := range backups {
+        totalSize += info.Size()
+        if (f.maxLogFile > 0 && i >= f.maxLogFile) ||
+            (f.maxTotalSize > 0 && totalSize > f.maxTotalSize) ||
+            (f.maxLogAge > 0 && now.Sub(info.ModTime()) > f.maxLogAge) {
+            os.Remove(paths[info])
         }
     }
 }
+
+// compress file with gzip and remove the original file
+func (f *FileTarget) compressFile(path string) {
+    src, e := os.Open(path)
+    if e != nil {
+        return
+    }
+
+    defer src.Close()
+
+    dst, e := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+    if e != nil {
+        panic(fmt.Sprintf("failed to create file: %s.gz, e: %s", path, e))
+    }
+
+    defer dst.Close()
+
+    gw := gzip.NewWriter(dst)
+    if _, e := io.Copy(gw, src); e != nil {
+        os.Remove(path + ".gz")
+        panic(fmt.Sprintf("failed to compress file: %s, e: %s", path, e))
+    }
+
+    if e := gw.Close(); e != nil {
+        os.Remove(path + ".gz")
+        panic(fmt.Sprintf("failed to compress file: %s, e: %s", path, e))
+    }
+
+    os.Remove(path)
+}
@@ -0,0 +1,93 @@
+package pgo
+
+import (
+    "crypto/subtle"
+    "fmt"
+    "strings"
+    "sync/atomic"
+)
+
+const (
+    // max num of debug lines returned in response header
+    maxDebugLines = 100
+    // max length of a debug line returned in response header
+    maxDebugLineLen = 1024
+)
+
+// SetDebugSecret set shared secret to escalate log level of a request,
+// escalation is disabled if secret is empty, default ""
+func (d *Log) SetDebugSecret(secret string) {
+    d.debugSecret = secret
+}
+
+// SetDebugHeader set header name to specify level of request,
+// token header is header name with "-Token" suffix, default "X-Debug-Level"
+func (d *Log) SetDebugHeader(header string) {
+    d.debugHeader = header
+}
+
+// SetDebugQuery set query name to specify level of request,
+// token query is query name with "Token" suffix, default "_debugLevel"
+func (d *Log) SetDebugQuery(query string) {
+    d.debugQuery = query
+}
+
+// SetDebugOutput set whether to return log lines of escalated
+// request in response header "X-Debug-Log", default false
+func (d *Log) SetDebugOutput(output bool) {
+    d.debugOutput = output
+}
+
+// SetDebugTarget set name of target to receive messages only enabled
+// by escalation, default "" means all targets handling the level
+func (d *Log) SetDebugTarget(target string) {
+    d.debugTarget = target
+}
+
+// escalate get levels for request of context, the level is specified
+// by debug header or query, and the token must match debug secret,
+// levels not lower than specified level will be enabled, 0 returned
+// if request is not escalated.
+func (d *Log) escalate(ctx *Context) int {
+    if len(d.debugSecret) == 0 || ctx.input == nil {
+        return 0
+    }
+
+    level := ctx.GetHeader(d.debugHeader, "")
+    token := ctx.GetHeader(d.debugHeader+"-Token", "")
+    if level == "" && d.debugQuery != "" {
+        level = ctx.GetQuery(d.debugQuery, "")
+        token = ctx.GetQuery(d.debugQuery+"Token", "")
+    }
+
+    if level == "" || subtle.ConstantTimeCompare([]byte(token), []byte(d.debugSecret)) != 1 {
+        return 0
+    }
+
+    min := LevelNone
+    switch strings.ToUpper(level) {
+    case "DEBUG", "INFO", "NOTICE", "WARN", "ERROR", "FATAL":
+        min = StringToLevel(level)
+    case "ALL":
+        min = LevelDebug
+    default:
+        return 0
+    }
+
+    return int(atomic.LoadInt32(&d.levels)) | (LevelAll &^ (min - 1))
+}
+
+// collect log line of escalated request for response header
+func (l *Logger) collect(item *LogItem) {
+    if len(l.debugLines) >= maxDebugLines {
+        return
+    }
+
+    line := fmt.Sprintf("[%s]%s %s", LevelToString(item.Level), item.Trace, item.Message)
+    line = strings.NewReplacer("\r", " ", "\n", " ").Replace(line)
+    if len(line) > maxDebugLineLen {
+        line = line[:maxDebugLineLen]
+    }
+
+    l.debugLines = append(l.debugLines, line)
+}
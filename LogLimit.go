@@ -0,0 +1,202 @@
+package pgo
+
+import (
+    "fmt"
+    "math/rand"
+    "runtime"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/pinguo/pgo/Util"
+)
+
+const (
+    limitByMessage = 0
+    limitBySite    = 1
+
+    // max num of keys tracked by limiter
+    maxLimitKeys = 10000
+)
+
+type limitBucket struct {
+    level      int
+    start      time.Time // start of current window
+    count      int       // num of messages in current window
+    suppressed uint64    // num of messages suppressed since last summary
+}
+
+// logLimiter rate limit and sample log messages per level, messages
+// are grouped by message format or call site, num of suppressed
+// messages is summarized periodically.
+type logLimiter struct {
+    limits   map[int]int
+    samples  map[int]float64
+    interval time.Duration
+    by       int
+
+    lock    sync.Mutex
+    buckets map[string]*limitBucket
+}
+
+func (l *logLimiter) enabled() bool {
+    return len(l.limits) > 0 || len(l.samples) > 0
+}
+
+// allow check whether message of level can be logged,
+// skip is the stack frames to the caller of logger.
+func (l *logLimiter) allow(level int, format string, skip int) bool {
+    limit, limited := l.limits[level]
+    rate, sampled := l.samples[level]
+    if !limited && !sampled {
+        return true
+    }
+
+    key := format
+    if l.by == limitBySite {
+        if _, file, line, ok := runtime.Caller(skip + 1); ok {
+            key = fmt.Sprintf("%s:%d", file, line)
+        }
+    }
+
+    key = LevelToString(level) + " " + key
+    now := time.Now()
+
+    l.lock.Lock()
+    defer l.lock.Unlock()
+
+    bucket := l.buckets[key]
+    if bucket == nil {
+        if len(l.buckets) >= maxLimitKeys {
+            return true
+        }
+
+        bucket = &limitBucket{level: level, start: now}
+        l.buckets[key] = bucket
+    }
+
+    if sampled && rand.Float64() >= rate {
+        bucket.suppressed++
+        return false
+    }
+
+    if limited {
+        if now.Sub(bucket.start) >= l.interval {
+            bucket.start, bucket.count = now, 0
+        }
+
+        if bucket.count++; bucket.count > limit {
+            bucket.suppressed++
+            return false
+        }
+    }
+
+    return true
+}
+
+// summary collect suppressed messages since last summary,
+// and remove buckets that are not active any more.
+func (l *logLimiter) summary() []*LogItem {
+    l.lock.Lock()
+    defer l.lock.Unlock()
+
+    items, now := make([]*LogItem, 0), time.Now()
+    for key, bucket := range l.buckets {
+        if bucket.suppressed > 0 {
+            items = append(items, &LogItem{
+                When:    now,
+                Level:   bucket.level,
+                Name:    App.GetName(),
+                Message: fmt.Sprintf("suppressed %d similar messages: %s", bucket.suppressed, key),
+            })
+            bucket.suppressed = 0
+        } else if now.Sub(bucket.start) >= l.interval {
+            delete(l.buckets, key)
+        }
+    }
+
+    return items
+}
+
+// SetRateLimit set max messages per rateInterval for levels,
+// eg. {"WARN": 100, "DEBUG,INFO": 1000}, default no limit
+func (d *Log) SetRateLimit(v map[string]interface{}) {
+    for levels, limit := range v {
+        for _, level := range d.splitLevels(levels) {
+            d.limiter.limits[level] = Util.ToInt(limit)
+        }
+    }
+}
+
+// SetRateInterval set window of rate limit, default "1s"
+func (d *Log) SetRateInterval(v string) {
+    if interval, e := time.ParseDuration(v); e != nil || interval <= 0 {
+        panic(fmt.Sprintf("Log: parse rateInterval error, val:%s, err:%v", v, e))
+    } else {
+        d.limiter.interval = interval
+    }
+}
+
+// SetSampling set probability to log message for levels,
+// eg. {"DEBUG": 0.01}, default no sampling
+func (d *Log) SetSampling(v map[string]interface{}) {
+    for levels, rate := range v {
+        for _, level := range d.splitLevels(levels) {
+            d.limiter.samples[level] = Util.ToFloat(rate)
+        }
+    }
+}
+
+// SetLimitBy set how messages are grouped for rate limit
+// and sampling(message, site), default "message"
+func (d *Log) SetLimitBy(by string) {
+    switch strings.ToUpper(by) {
+    case "MESSAGE":
+        d.limiter.by = limitByMessage
+    case "SITE":
+        d.limiter.by = limitBySite
+    default:
+        panic("Log: invalid limitBy: " + by)
+    }
+}
+
+// SetSummaryInterval set interval to log suppressed summary, default "60s"
+func (d *Log) SetSummaryInterval(v string) {
+    if interval, e := time.ParseDuration(v); e != nil || interval <= 0 {
+        panic(fmt.Sprintf("Log: parse summaryInterval error, val:%s, err:%v", v, e))
+    } else {
+        d.summaryInterval = interval
+    }
+}
+
+// split levels string to single levels
+func (d *Log) splitLevels(str string) []int {
+    levels, mask := make([]int, 0), parseLevels(str)
+    for level := LevelDebug; level <= LevelFatal; level <<= 1 {
+        if mask&level != 0 {
+            levels = append(levels, level)
+        }
+    }
+
+    return levels
+}
+
+func (d *Log) summaryLoop() {
+    timer := time.NewTicker(d.summaryInterval)
+    defer timer.Stop()
+    defer d.summaryWg.Done()
+
+    for {
+        select {
+        case <-timer.C:
+            for _, item := range d.limiter.summary() {
+                d.dispatch(item)
+            }
+        case <-d.summaryDone:
+            for _, item := range d.limiter.summary() {
+                d.dispatch(item)
+            }
+            return
+        }
+    }
+}
@@ -0,0 +1,38 @@
+// +build !windows
+
+package pgo
+
+import (
+    "os"
+    "os/signal"
+    "sync"
+    "syscall"
+)
+
+var (
+    reopenLock    sync.Mutex
+    reopenTargets []*FileTarget
+)
+
+// notifyReopen register file target to be reopened on SIGUSR1
+func notifyReopen(f *FileTarget) {
+    reopenLock.Lock()
+    defer reopenLock.Unlock()
+
+    if reopenTargets == nil {
+        sig := make(chan os.Signal, 1)
+        signal.Notify(sig, syscall.SIGUSR1)
+
+        go func() {
+            for range sig {
+                reopenLock.Lock()
+                for _, target := range reopenTargets {
+                    target.Reopen()
+                }
+                reopenLock.Unlock()
+            }
+        }()
+    }
+
+    reopenTargets = append(reopenTargets, f)
+}
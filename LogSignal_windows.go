@@ -0,0 +1,5 @@
+package pgo
+
+// notifyReopen SIGUSR1 is not supported on windows
+func notifyReopen(f *FileTarget) {
+}
@@ -0,0 +1,565 @@
+package pgo
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "net"
+    "net/http"
+    "os"
+    "strings"
+    "time"
+)
+
+const (
+    facilityKern   = 0
+    facilityUser   = 1
+    facilityDaemon = 3
+    facilityLocal0 = 16
+)
+
+// LevelToSeverity convert int level to syslog severity
+func LevelToSeverity(level int) int {
+    switch level {
+    case LevelDebug:
+        return 7
+    case LevelInfo:
+        return 6
+    case LevelNotice:
+        return 5
+    case LevelWarn:
+        return 4
+    case LevelError:
+        return 3
+    case LevelFatal:
+        return 2
+    default:
+        return 6
+    }
+}
+
+// logQueue bounded queue of formatted log data,
+// the oldest data will be dropped if queue is full.
+type logQueue struct {
+    items   [][]byte
+    maxLen  int
+    dropped uint64
+}
+
+func (q *logQueue) push(data []byte) {
+    if q.maxLen > 0 && len(q.items) >= q.maxLen {
+        q.items[0] = nil
+        q.items = q.items[1:]
+        q.dropped++
+    }
+
+    q.items = append(q.items, data)
+}
+
+func (q *logQueue) shift(n int) {
+    for i := 0; i < n; i++ {
+        q.items[i] = nil
+    }
+
+    q.items = q.items[n:]
+}
+
+func (q *logQueue) len() int {
+    return len(q.items)
+}
+
+// netWriter connection of stream based targets, reconnect
+// on write failure and keep unsent data in a bounded queue.
+type netWriter struct {
+    network       string
+    address       string
+    netTimeout    time.Duration
+    retryInterval time.Duration
+
+    conn     net.Conn
+    lastDial time.Time
+    queue    logQueue
+}
+
+func (w *netWriter) isStream() bool {
+    switch w.network {
+    case "tcp", "tcp4", "tcp6", "unix":
+        return true
+    }
+
+    return false
+}
+
+func (w *netWriter) connect() bool {
+    if w.conn != nil {
+        return true
+    }
+
+    // avoid dialing too frequently when remote is down
+    if time.Since(w.lastDial) < w.retryInterval {
+        return false
+    }
+
+    w.lastDial = time.Now()
+    if conn, e := net.DialTimeout(w.network, w.address, w.netTimeout); e == nil {
+        w.conn = conn
+        return true
+    }
+
+    return false
+}
+
+func (w *netWriter) close() {
+    if w.conn != nil {
+        w.conn.Close()
+        w.conn = nil
+    }
+}
+
+// flush write queued data to remote, data failed to
+// write will be kept in queue for the next retry.
+func (w *netWriter) flush() {
+    if w.queue.len() == 0 || !w.connect() {
+        return
+    }
+
+    sent := 0
+    for _, data := range w.queue.items {
+        w.conn.SetWriteDeadline(time.Now().Add(w.netTimeout))
+        if _, e := w.conn.Write(data); e != nil {
+            w.close()
+            break
+        }
+        sent++
+    }
+
+    w.queue.shift(sent)
+}
+
+// SyslogTarget target for syslog, message is formatted as RFC5424,
+// tcp and unix stream use octet counting framing(RFC6587), configuration:
+// syslog:
+//     class: "@pgo/SyslogTarget"
+//     levels: "ALL"
+//     network: "udp"
+//     address: "127.0.0.1:514"
+//     facility: "local0"
+//     tag: "app-name"
+//     netTimeout: "1s"
+//     retryInterval: "5s"
+//     maxBufferLine: 100
+//     maxRetryLine: 10000
+type SyslogTarget struct {
+    Target
+    netWriter
+    facility      int
+    tag           string
+    hostname      string
+    maxBufferLine int
+}
+
+func (s *SyslogTarget) Construct() {
+    s.levels = LevelAll
+    s.network = "udp"
+    s.address = "127.0.0.1:514"
+    s.facility = facilityLocal0
+    s.netTimeout = time.Second
+    s.retryInterval = 5 * time.Second
+    s.maxBufferLine = 100
+    s.queue.maxLen = 10000
+}
+
+func (s *SyslogTarget) Init() {
+    if len(s.tag) == 0 {
+        s.tag = App.GetName()
+    }
+
+    if s.hostname, _ = os.Hostname(); len(s.hostname) == 0 {
+        s.hostname = "-"
+    }
+}
+
+// SetNetwork set network of syslog server(unix, unixgram, udp, tcp), default "udp"
+func (s *SyslogTarget) SetNetwork(network string) {
+    s.network = network
+}
+
+// SetAddress set address of syslog server, eg. "/dev/log", "127.0.0.1:514"
+func (s *SyslogTarget) SetAddress(address string) {
+    s.address = address
+}
+
+// SetFacility set syslog facility(kern, user, daemon, local0~local7), default "local0"
+func (s *SyslogTarget) SetFacility(facility string) {
+    switch f := strings.ToLower(facility); {
+    case f == "kern":
+        s.facility = facilityKern
+    case f == "user":
+        s.facility = facilityUser
+    case f == "daemon":
+        s.facility = facilityDaemon
+    case len(f) == 6 && strings.HasPrefix(f, "local") && f[5] >= '0' && f[5] <= '7':
+        s.facility = facilityLocal0 + int(f[5]-'0')
+    default:
+        panic("SyslogTarget: invalid facility: " + facility)
+    }
+}
+
+// SetTag set app name of syslog message, default is app name
+func (s *SyslogTarget) SetTag(tag string) {
+    s.tag = tag
+}
+
+// SetNetTimeout set timeout to dial and write, default "1s"
+func (s *SyslogTarget) SetNetTimeout(v string) {
+    if netTimeout, e := time.ParseDuration(v); e != nil {
+        panic(fmt.Sprintf("SyslogTarget: parse netTimeout error, val:%s, err:%s", v, e.Error()))
+    } else {
+        s.netTimeout = netTimeout
+    }
+}
+
+// SetRetryInterval set min interval to reconnect, default "5s"
+func (s *SyslogTarget) SetRetryInterval(v string) {
+    if retryInterval, e := time.ParseDuration(v); e != nil {
+        panic(fmt.Sprintf("SyslogTarget: parse retryInterval error, val:%s, err:%s", v, e.Error()))
+    } else {
+        s.retryInterval = retryInterval
+    }
+}
+
+// SetMaxBufferLine set max buffer lines before write, default 100
+func (s *SyslogTarget) SetMaxBufferLine(maxBufferLine int) {
+    s.maxBufferLine = maxBufferLine
+}
+
+// SetMaxRetryLine set max lines kept for retry, default 10000
+func (s *SyslogTarget) SetMaxRetryLine(maxRetryLine int) {
+    s.queue.maxLen = maxRetryLine
+}
+
+// GetDropped get num of lines dropped because of retry queue overflow
+func (s *SyslogTarget) GetDropped() uint64 {
+    return s.queue.dropped
+}
+
+// Process format log item to RFC5424 message and buffer it,
+// buffer will be written to syslog server if it is full.
+func (s *SyslogTarget) Process(item *LogItem) {
+    if !s.IsHandling(item.Level) {
+        return
+    }
+
+    s.queue.push(s.message(item))
+    if s.queue.len() >= s.maxBufferLine {
+        s.Flush(false)
+    }
+}
+
+// Flush write buffered messages to syslog server
+func (s *SyslogTarget) Flush(final bool) {
+    s.flush()
+    if final {
+        s.close()
+    }
+}
+
+func (s *SyslogTarget) message(item *LogItem) []byte {
+    var msg string
+    if s.formatter != nil {
+        msg = strings.TrimRight(s.formatter.Format(item), "\n")
+    } else {
+        msg = fmt.Sprintf("[%s][%s]%s: %s", item.LogId, item.Name, item.Trace, item.Message)
+    }
+
+    // <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+    buf := &bytes.Buffer{}
+    fmt.Fprintf(buf, "<%d>1 %s %s %s %d %s - %s",
+        s.facility*8+LevelToSeverity(item.Level),
+        item.When.Format("2006-01-02T15:04:05.000000Z07:00"),
+        s.hostname,
+        s.tag,
+        os.Getpid(),
+        LevelToString(item.Level),
+        msg,
+    )
+
+    if !s.isStream() {
+        return buf.Bytes()
+    }
+
+    return append([]byte(fmt.Sprintf("%d ", buf.Len())), buf.Bytes()...)
+}
+
+// StreamTarget target for newline delimited tcp/udp stream,
+// eg. fluentd or logstash tcp/udp input, configuration:
+// stream:
+//     class: "@pgo/StreamTarget"
+//     levels: "ALL"
+//     network: "tcp"
+//     address: "127.0.0.1:5170"
+//     netTimeout: "1s"
+//     retryInterval: "5s"
+//     maxBufferLine: 100
+//     maxRetryLine: 10000
+type StreamTarget struct {
+    Target
+    netWriter
+    maxBufferLine int
+}
+
+func (s *StreamTarget) Construct() {
+    s.levels = LevelAll
+    s.network = "tcp"
+    s.netTimeout = time.Second
+    s.retryInterval = 5 * time.Second
+    s.maxBufferLine = 100
+    s.queue.maxLen = 10000
+}
+
+func (s *StreamTarget) Init() {
+    if len(s.address) == 0 {
+        panic("StreamTarget: address is required")
+    }
+}
+
+// SetNetwork set network of remote(tcp, udp), default "tcp"
+func (s *StreamTarget) SetNetwork(network string) {
+    s.network = network
+}
+
+// SetAddress set address of remote, eg. "127.0.0.1:5170"
+func (s *StreamTarget) SetAddress(address string) {
+    s.address = address
+}
+
+// SetNetTimeout set timeout to dial and write, default "1s"
+func (s *StreamTarget) SetNetTimeout(v string) {
+    if netTimeout, e := time.ParseDuration(v); e != nil {
+        panic(fmt.Sprintf("StreamTarget: parse netTimeout error, val:%s, err:%s", v, e.Error()))
+    } else {
+        s.netTimeout = netTimeout
+    }
+}
+
+// SetRetryInterval set min interval to reconnect, default "5s"
+func (s *StreamTarget) SetRetryInterval(v string) {
+    if retryInterval, e := time.ParseDuration(v); e != nil {
+        panic(fmt.Sprintf("StreamTarget: parse retryInterval error, val:%s, err:%s", v, e.Error()))
+    } else {
+        s.retryInterval = retryInterval
+    }
+}
+
+// SetMaxBufferLine set max buffer lines before write, default 100
+func (s *StreamTarget) SetMaxBufferLine(maxBufferLine int) {
+    s.maxBufferLine = maxBufferLine
+}
+
+// SetMaxRetryLine set max lines kept for retry, default 10000
+func (s *StreamTarget) SetMaxRetryLine(maxRetryLine int) {
+    s.queue.maxLen = maxRetryLine
+}
+
+// GetDropped get num of lines dropped because of retry queue overflow
+func (s *StreamTarget) GetDropped() uint64 {
+    return s.queue.dropped
+}
+
+// Process format log item to a line and buffer it,
+// buffer will be written to remote if it is full.
+func (s *StreamTarget) Process(item *LogItem) {
+    if !s.IsHandling(item.Level) {
+        return
+    }
+
+    line := s.Format(item)
+    if !strings.HasSuffix(line, "\n") {
+        line += "\n"
+    }
+
+    s.queue.push([]byte(line))
+    if s.queue.len() >= s.maxBufferLine {
+        s.Flush(false)
+    }
+}
+
+// Flush write buffered lines to remote
+func (s *StreamTarget) Flush(final bool) {
+    s.flush()
+    if final {
+        s.close()
+    }
+}
+
+// HttpTarget target for http bulk api, items are batched and
+// posted as json array, if formatter is specified, formatted
+// lines are posted as plain text, configuration:
+// http:
+//     class: "@pgo/HttpTarget"
+//     levels: "ALL"
+//     url: "http://127.0.0.1:8080/logs"
+//     headers:
+//         Authorization: "Basic xxx"
+//     timeout: "3s"
+//     retryInterval: "5s"
+//     maxBatchItems: 500
+//     maxRetryItems: 10000
+type HttpTarget struct {
+    Target
+    url           string
+    headers       map[string]string
+    timeout       time.Duration
+    retryInterval time.Duration
+    maxBatchItems int
+
+    client   *http.Client
+    lastFail time.Time
+    queue    logQueue
+}
+
+func (h *HttpTarget) Construct() {
+    h.levels = LevelAll
+    h.headers = make(map[string]string)
+    h.timeout = 3 * time.Second
+    h.retryInterval = 5 * time.Second
+    h.maxBatchItems = 500
+    h.queue.maxLen = 10000
+}
+
+func (h *HttpTarget) Init() {
+    if len(h.url) == 0 {
+        panic("HttpTarget: url is required")
+    }
+
+    h.client = &http.Client{Timeout: h.timeout}
+}
+
+// SetUrl set url of bulk api
+func (h *HttpTarget) SetUrl(url string) {
+    h.url = url
+}
+
+// SetHeaders set extra request headers
+func (h *HttpTarget) SetHeaders(headers map[string]interface{}) {
+    for k, v := range headers {
+        h.headers[k] = fmt.Sprint(v)
+    }
+}
+
+// SetTimeout set timeout of each post, default "3s"
+func (h *HttpTarget) SetTimeout(v string) {
+    if timeout, e := time.ParseDuration(v); e != nil {
+        panic(fmt.Sprintf("HttpTarget: parse timeout error, val:%s, err:%s", v, e.Error()))
+    } else {
+        h.timeout = timeout
+    }
+}
+
+// SetRetryInterval set min interval to retry after a failed post, default "5s"
+func (h *HttpTarget) SetRetryInterval(v string) {
+    if retryInterval, e := time.ParseDuration(v); e != nil {
+        panic(fmt.Sprintf("HttpTarget: parse retryInterval error, val:%s, err:%s", v, e.Error()))
+    } else {
+        h.retryInterval = retryInterval
+    }
+}
+
+// SetMaxBatchItems set max items of each post, default 500
+func (h *HttpTarget) SetMaxBatchItems(maxBatchItems int) {
+    h.maxBatchItems = maxBatchItems
+}
+
+// SetMaxRetryItems set max items kept for retry, default 10000
+func (h *HttpTarget) SetMaxRetryItems(maxRetryItems int) {
+    h.queue.maxLen = maxRetryItems
+}
+
+// GetDropped get num of items dropped because of retry queue overflow
+func (h *HttpTarget) GetDropped() uint64 {
+    return h.queue.dropped
+}
+
+// Process encode log item and buffer it, buffer
+// will be posted if it reaches max batch items.
+func (h *HttpTarget) Process(item *LogItem) {
+    if !h.IsHandling(item.Level) {
+        return
+    }
+
+    var data []byte
+    if h.formatter != nil {
+        data = []byte(h.formatter.Format(item))
+    } else {
+        data, _ = json.Marshal(map[string]interface{}{
+            "time":    item.When.Format(time.RFC3339Nano),
+            "level":   LevelToString(item.Level),
+            "name":    item.Name,
+            "logId":   item.LogId,
+            "trace":   item.Trace,
+            "message": item.Message,
+        })
+    }
+
+    h.queue.push(data)
+    if h.queue.len() >= h.maxBatchItems {
+        h.Flush(false)
+    }
+}
+
+// Flush post buffered items in batches, the failed
+// batch and the rest items will be kept for retry,
+// posting is skipped in retry interval after a failure
+// unless it's the final flush.
+func (h *HttpTarget) Flush(final bool) {
+    // avoid posting too frequently when remote is down
+    if !final && time.Since(h.lastFail) < h.retryInterval {
+        return
+    }
+
+    for h.queue.len() > 0 {
+        n := h.queue.len()
+        if n > h.maxBatchItems {
+            n = h.maxBatchItems
+        }
+
+        if !h.post(h.queue.items[:n]) {
+            h.lastFail = time.Now()
+            return
+        }
+
+        h.queue.shift(n)
+    }
+}
+
+func (h *HttpTarget) post(items [][]byte) bool {
+    body, contentType := &bytes.Buffer{}, "application/json"
+    if h.formatter != nil {
+        for _, data := range items {
+            body.Write(data)
+        }
+        contentType = "text/plain; charset=utf-8"
+    } else {
+        body.WriteByte('[')
+        body.Write(bytes.Join(items, []byte{','}))
+        body.WriteByte(']')
+    }
+
+    req, e := http.NewRequest(http.MethodPost, h.url, body)
+    if e != nil {
+        return false
+    }
+
+    req.Header.Set("Content-Type", contentType)
+    for k, v := range h.headers {
+        req.Header.Set(k, v)
+    }
+
+    res, e := h.client.Do(req)
+    if e != nil {
+        return false
+    }
+
+    res.Body.Close()
+    return res.StatusCode >= 200 && res.StatusCode < 300
+}
@@ -0,0 +1,120 @@
+package pgo
+
+import (
+    "encoding/json"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "sync/atomic"
+    "testing"
+    "time"
+)
+
+// register test flags and set base path to testdata
+// before App parses command line and loads config in init
+var _ = func() bool {
+    testing.Init()
+    basePath, _ := filepath.Abs("testdata")
+    os.Setenv("PgoTestAppBasePath", basePath)
+    return true
+}()
+
+func newTestHttpTarget(url string) *HttpTarget {
+    h := &HttpTarget{}
+    h.Construct()
+    h.SetUrl(url)
+    h.SetMaxBatchItems(2)
+    h.Init()
+    return h
+}
+
+func TestHttpTargetPost(t *testing.T) {
+    var body []byte
+    var contentType string
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ = ioutil.ReadAll(r.Body)
+        contentType = r.Header.Get("Content-Type")
+    }))
+    defer srv.Close()
+
+    h := newTestHttpTarget(srv.URL)
+    h.Process(&LogItem{When: time.Now(), Level: LevelInfo, Name: "app", Message: "a"})
+    if body != nil {
+        t.Fatalf("posted before batch is full: %s", body)
+    }
+
+    h.Process(&LogItem{When: time.Now(), Level: LevelInfo, Name: "app", Message: "b"})
+
+    var items []map[string]interface{}
+    if e := json.Unmarshal(body, &items); e != nil {
+        t.Fatalf("invalid body: %s, %s", body, e)
+    }
+
+    if len(items) != 2 || items[0]["message"] != "a" || items[1]["level"] != "INFO" {
+        t.Errorf("unexpected items: %v", items)
+    }
+
+    if contentType != "application/json" {
+        t.Errorf("unexpected content type: %s", contentType)
+    }
+
+    if h.queue.len() != 0 {
+        t.Errorf("queue not empty after post: %d", h.queue.len())
+    }
+}
+
+func TestHttpTargetRetry(t *testing.T) {
+    var posts, fail int32 = 0, 1
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&posts, 1)
+        if atomic.LoadInt32(&fail) == 1 {
+            w.WriteHeader(http.StatusServiceUnavailable)
+        }
+    }))
+    defer srv.Close()
+
+    h := newTestHttpTarget(srv.URL)
+    h.SetRetryInterval("50ms")
+    for i := 0; i < 10; i++ {
+        h.Process(&LogItem{When: time.Now(), Level: LevelInfo, Message: "x"})
+    }
+
+    if n := atomic.LoadInt32(&posts); n != 1 {
+        t.Errorf("expect 1 post in retry interval, got %d", n)
+    }
+
+    if h.queue.len() != 10 {
+        t.Errorf("expect 10 items kept for retry, got %d", h.queue.len())
+    }
+
+    atomic.StoreInt32(&fail, 0)
+    time.Sleep(60 * time.Millisecond)
+    h.Flush(false)
+
+    if h.queue.len() != 0 {
+        t.Errorf("expect queue flushed after retry interval, got %d", h.queue.len())
+    }
+}
+
+func TestHttpTargetFinalFlush(t *testing.T) {
+    var posts int32
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        atomic.AddInt32(&posts, 1)
+    }))
+    defer srv.Close()
+
+    h := newTestHttpTarget(srv.URL)
+    h.lastFail = time.Now()
+    h.Process(&LogItem{When: time.Now(), Level: LevelInfo, Message: "x"})
+    h.Flush(false)
+    if atomic.LoadInt32(&posts) != 0 {
+        t.Fatal("posted in retry interval")
+    }
+
+    h.Flush(true)
+    if atomic.LoadInt32(&posts) != 1 || h.queue.len() != 0 {
+        t.Errorf("final flush should ignore retry interval, posts: %d", posts)
+    }
+}
@@ -3,6 +3,7 @@ package pgo
 import (
     "regexp"
     "strings"
+    "sync"
 
     "github.com/pinguo/pgo/Util"
 )
@@ -23,7 +24,7 @@ type routeRule struct {
     route   string
 }
 
-// Router the router component, configuration:
+// Router the router component, rules are reloaded on config change, configuration:
 // router:
 //     rules:
 //         - "^/foo/all$ => /foo/index"
@@ -31,6 +32,7 @@ type routeRule struct {
 type Router struct {
     reFmt *regexp.Regexp
     rules []routeRule
+    lock  sync.RWMutex
 }
 
 func (r *Router) Construct() {
@@ -57,15 +59,37 @@ func (r *Router) SetRules(rules []interface{}) {
 func (r *Router) AddRoute(pattern, route string) {
     rePat := regexp.MustCompile(pattern)
     rule := routeRule{rePat, pattern, route}
+
+    r.lock.Lock()
+    defer r.lock.Unlock()
+
     r.rules = append(r.rules, rule)
 }
 
+// Reload replace rules on config change
+func (r *Router) Reload(config map[string]interface{}) {
+    tmp := &Router{}
+    tmp.Construct()
+    if rules, ok := config["rules"].([]interface{}); ok {
+        tmp.SetRules(rules)
+    }
+
+    r.lock.Lock()
+    defer r.lock.Unlock()
+
+    r.rules = tmp.rules
+}
+
 // Resolve path to route and action params, then format route to CamelCase
 func (r *Router) Resolve(path string) (route string, params []string) {
     path = Util.CleanPath(path)
 
-    if len(r.rules) != 0 {
-        for _, rule := range r.rules {
+    r.lock.RLock()
+    rules := r.rules
+    r.lock.RUnlock()
+
+    if len(rules) != 0 {
+        for _, rule := range rules {
             matches := rule.rePat.FindStringSubmatch(path)
             if len(matches) != 0 {
                 path = rule.route
@@ -142,12 +142,13 @@ func (s *Server) SetPlugins(v []interface{}) {
 
 // ServerStats server stats
 type ServerStats struct {
-    MemMB   uint   // memory obtained from os
-    NumReq  uint64 // number of handled requests
-    NumGO   uint   // number of goroutines
-    NumGC   uint   // number of gc runs
-    TimeGC  string // total time of gc pause
-    TimeRun string // total time of app runs
+    MemMB      uint   // memory obtained from os
+    NumReq     uint64 // number of handled requests
+    NumGO      uint   // number of goroutines
+    NumGC      uint   // number of gc runs
+    TimeGC     string // total time of gc pause
+    TimeRun    string // total time of app runs
+    LogDropped uint64 // number of log items dropped
 }
 
 // GetStats get server stats
@@ -163,12 +164,13 @@ func (s *Server) GetStats() *ServerStats {
     }
 
     return &ServerStats{
-        MemMB:   uint(memStats.Sys / (1 << 20)),
-        NumReq:  atomic.LoadUint64(&s.numReq),
-        NumGO:   uint(runtime.NumGoroutine()),
-        NumGC:   uint(memStats.NumGC),
-        TimeGC:  timeGC.String(),
-        TimeRun: TimeRun().String(),
+        MemMB:      uint(memStats.Sys / (1 << 20)),
+        NumReq:     atomic.LoadUint64(&s.numReq),
+        NumGO:      uint(runtime.NumGoroutine()),
+        NumGC:      uint(memStats.NumGC),
+        TimeGC:     timeGC.String(),
+        TimeRun:    TimeRun().String(),
+        LogDropped: App.GetLog().GetDropped(),
     }
 }
 
@@ -176,6 +178,10 @@ func (s *Server) GetStats() *ServerStats {
 func (s *Server) Serve() {
     // flush log when app end
     defer App.GetLog().Flush()
+    // stop watching config when app end
+    defer App.GetConfig().StopWatch()
+    // stop components when app end
+    defer App.Stop()
     // exec stopBefore when app end
     defer App.GetStopBefore().Exec()
 
@@ -238,7 +244,7 @@ func (s *Server) HandleRequest(ctx *Context) {
     // get new controller bind to this route
     rv, action := s.createController(route, ctx)
     if !rv.IsValid() {
-        ctx.End(http.StatusNotFound, []byte("route not found"))
+        App.GetErrorHandler().Handle(ctx, NewException(http.StatusNotFound, "route not found"), "text")
         return
     }
 
@@ -261,14 +267,17 @@ func (s *Server) HandleRequest(ctx *Context) {
     defer func() {
         // process controller panic
         if v := recover(); v != nil {
+            App.GetEventBus().triggerSafe(EventPanic, ctx, v)
             controller.HandlePanic(v)
         }
 
         // after action hook
         controller.AfterAction(actionId)
+        App.GetEventBus().triggerSafe(EventActionAfter, ctx, controller, actionId)
     }()
 
     // before action hook
+    App.GetEventBus().Trigger(EventActionBefore, ctx, controller, actionId)
     controller.BeforeAction(actionId)
 
     // call action method
@@ -284,7 +293,7 @@ func (s *Server) handleHttp(wg *sync.WaitGroup) {
     s.servers = append(s.servers, svr)
     wg.Add(1)
 
-    GLogger().Info("start running http at " + svr.Addr)
+    GLogger().Info("start running http at %s", svr.Addr)
 
     go func() {
         if err := svr.ListenAndServe(); err != http.ErrServerClosed {
@@ -304,7 +313,7 @@ func (s *Server) handleHttps(wg *sync.WaitGroup) {
     s.servers = append(s.servers, svr)
     wg.Add(1)
 
-    GLogger().Info("start running https at " + svr.Addr)
+    GLogger().Info("start running https at %s", svr.Addr)
 
     go func() {
         if err := svr.ListenAndServeTLS(s.crtFile, s.keyFile); err != http.ErrServerClosed {
@@ -333,7 +342,7 @@ func (s *Server) handleDebug(wg *sync.WaitGroup) {
     s.servers = append(s.servers, svr)
     wg.Add(1)
 
-    GLogger().Info("start running debug at " + svr.Addr)
+    GLogger().Info("start running debug at %s", svr.Addr)
 
     go func() {
         if err := svr.ListenAndServe(); err != http.ErrServerClosed {
@@ -349,7 +358,7 @@ func (s *Server) handleSignal(wg *sync.WaitGroup) {
     go func() {
         <-sig // wait signal
         for _, svr := range s.servers {
-            GLogger().Info("stop running " + svr.Addr)
+            GLogger().Info("stop running %s", svr.Addr)
             ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
             svr.Shutdown(ctx)
             wg.Done()
@@ -364,7 +373,7 @@ func (s *Server) handleStats(wg *sync.WaitGroup) {
         for {
             <-timer // wait timer
             data, _ := json.Marshal(s.GetStats())
-            GLogger().Info("app stats: " + string(data))
+            GLogger().Info("app stats: %s", string(data))
         }
     }()
 }
@@ -3,11 +3,13 @@ package pgo
 import (
     "fmt"
     "net/http"
+    "sync"
 
     "github.com/pinguo/pgo/Util"
 )
 
-// Status the status component, configuration:
+// Status the status component, useI18n and mapping
+// are reloaded on config change, configuration:
 // status:
 //     useI18n: false
 //     mapping:
@@ -15,6 +17,7 @@ import (
 type Status struct {
     useI18n bool
     mapping map[int]string
+    lock    sync.RWMutex
 }
 
 func (s *Status) Construct() {
@@ -34,9 +37,25 @@ func (s *Status) SetMapping(m map[string]interface{}) {
     }
 }
 
+// Reload replace useI18n and mapping on config change
+func (s *Status) Reload(config map[string]interface{}) {
+    tmp := &Status{}
+    tmp.Construct()
+    Configure(tmp, config)
+
+    s.lock.Lock()
+    defer s.lock.Unlock()
+
+    s.useI18n, s.mapping = tmp.useI18n, tmp.mapping
+}
+
 // GetText get status text
 func (s *Status) GetText(status int, ctx *Context, dft ...string) string {
+    s.lock.RLock()
     txt, ok := s.mapping[status]
+    useI18n := s.useI18n
+    s.lock.RUnlock()
+
     if !ok {
         if len(dft) == 0 || len(dft[0]) == 0 {
             if txt = http.StatusText(status); len(txt) == 0 {
@@ -47,7 +66,7 @@ func (s *Status) GetText(status int, ctx *Context, dft ...string) string {
         }
     }
 
-    if s.useI18n && ctx != nil {
+    if useI18n && ctx != nil {
         al := ctx.GetHeader("Accept-Language", "")
         txt = App.GetI18n().Translate(txt, al)
     }
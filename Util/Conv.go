@@ -124,6 +124,38 @@ func ToString(v interface{}) string {
     }
 }
 
+// ParseSize parse size string to num of bytes, eg. "512", "10KB", "1.5g",
+// unit(B, K/KB, M/MB, G/GB, T/TB) is case-insensitive and 1024 based.
+func ParseSize(s string) (int64, error) {
+    str := strings.ToUpper(strings.TrimSpace(s))
+    str = strings.TrimSuffix(str, "B")
+
+    unit := int64(1)
+    if n := len(str); n > 0 {
+        switch str[n-1] {
+        case 'K':
+            unit = 1 << 10
+        case 'M':
+            unit = 1 << 20
+        case 'G':
+            unit = 1 << 30
+        case 'T':
+            unit = 1 << 40
+        }
+
+        if unit > 1 {
+            str = str[:n-1]
+        }
+    }
+
+    f64, e := strconv.ParseFloat(strings.TrimSpace(str), 64)
+    if e != nil || f64 < 0 {
+        return 0, fmt.Errorf("invalid size: %s", s)
+    }
+
+    return int64(f64 * float64(unit)), nil
+}
+
 func str2bool(s string) bool {
     s = strings.TrimSpace(s)
     if b, e := strconv.ParseBool(s); e == nil {
@@ -0,0 +1,57 @@
+package Util
+
+import (
+    "bufio"
+    "bytes"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// ParseDotEnv parse content of .env file, format: KEY=VALUE,
+// optional "export " prefix, comments start with '#', value
+// can be single or double quoted, escapes in double quoted
+// value are decoded.
+func ParseDotEnv(data []byte) (map[string]string, error) {
+    vars := make(map[string]string)
+
+    scanner := bufio.NewScanner(bytes.NewReader(data))
+    for line := 1; scanner.Scan(); line++ {
+        text := strings.TrimSpace(scanner.Text())
+        if len(text) == 0 || text[0] == '#' {
+            continue
+        }
+
+        text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+        pos := strings.IndexByte(text, '=')
+        if pos <= 0 {
+            return nil, fmt.Errorf("dotenv: line %d: expect KEY=VALUE", line)
+        }
+
+        key, val := strings.TrimSpace(text[:pos]), strings.TrimSpace(text[pos+1:])
+        switch {
+        case len(val) >= 2 && val[0] == '"':
+            end := strings.LastIndexByte(val, '"')
+            v, e := strconv.Unquote(val[:end+1])
+            if e != nil {
+                return nil, fmt.Errorf("dotenv: line %d: invalid quoted value", line)
+            }
+            val = v
+        case len(val) >= 2 && val[0] == '\'':
+            end := strings.LastIndexByte(val, '\'')
+            if end <= 0 {
+                return nil, fmt.Errorf("dotenv: line %d: invalid quoted value", line)
+            }
+            val = val[1:end]
+        default:
+            // strip inline comment
+            if pos := strings.Index(val, " #"); pos >= 0 {
+                val = strings.TrimSpace(val[:pos])
+            }
+        }
+
+        vars[key] = val
+    }
+
+    return vars, scanner.Err()
+}
@@ -0,0 +1,59 @@
+package Util
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+    tests := []struct {
+        name string
+        doc  string
+        want map[string]string
+    }{
+        {"empty", "", map[string]string{}},
+        {"comments", "# comment\n\n  # indented\n", map[string]string{}},
+        {"plain", "A=1\nB = two words \nC=\n", map[string]string{"A": "1", "B": "two words", "C": ""}},
+        {"export", "export A=1\nexport  B=2\n", map[string]string{"A": "1", "B": "2"}},
+        {"inline comment", "A=x # comment\nB=x#y\n", map[string]string{"A": "x", "B": "x#y"}},
+        {"equal in value", "DSN=user:pass@tcp(host)/db?a=b\n", map[string]string{"DSN": "user:pass@tcp(host)/db?a=b"}},
+        {"double quoted", "A=\"se\\tcret\"\nB=\"a # b\"\nC=\"\"\n", map[string]string{"A": "se\tcret", "B": "a # b", "C": ""}},
+        {"double quoted with comment", "A=\"x\" # comment\n", map[string]string{"A": "x"}},
+        {"single quoted", "A='raw\\t$X'\nB='a # b'\n", map[string]string{"A": "raw\\t$X", "B": "a # b"}},
+        {"later wins", "A=1\nA=2\n", map[string]string{"A": "2"}},
+        {"crlf", "A=1\r\nB=2\r\n", map[string]string{"A": "1", "B": "2"}},
+    }
+
+    for _, tt := range tests {
+        got, e := ParseDotEnv([]byte(tt.doc))
+        if e != nil {
+            t.Errorf("%s: unexpected error: %s", tt.name, e)
+        } else if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestParseDotEnvError(t *testing.T) {
+    tests := []struct {
+        name string
+        doc  string
+        err  string
+    }{
+        {"missing equal", "A=1\nB\n", "dotenv: line 2: expect KEY=VALUE"},
+        {"missing key", "=1\n", "dotenv: line 1: expect KEY=VALUE"},
+        {"unterminated double quote", "A=\"abc\n", "dotenv: line 1: invalid quoted value"},
+        {"invalid escape", "A=\"\\q\"\n", "dotenv: line 1: invalid quoted value"},
+        {"unterminated single quote", "A='abc\n", "dotenv: line 1: invalid quoted value"},
+    }
+
+    for _, tt := range tests {
+        _, e := ParseDotEnv([]byte(tt.doc))
+        if e == nil {
+            t.Errorf("%s: expect error", tt.name)
+        } else if !strings.Contains(e.Error(), tt.err) {
+            t.Errorf("%s: got error %q, want %q", tt.name, e, tt.err)
+        }
+    }
+}
@@ -0,0 +1,112 @@
+package Util
+
+import (
+    "bufio"
+    "bytes"
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+// IniUnmarshal parse ini document to map, section name is split by
+// dot to nested map, key ends with "[]" is appended to list, unquoted
+// values like true, false, on, off and numbers are decoded as bool,
+// int and float64, comments start with ';' or '#'.
+func IniUnmarshal(data []byte) (map[string]interface{}, error) {
+    root := make(map[string]interface{})
+    section := root
+
+    scanner := bufio.NewScanner(bytes.NewReader(data))
+    for line := 1; scanner.Scan(); line++ {
+        text := strings.TrimSpace(scanner.Text())
+        if len(text) == 0 || text[0] == ';' || text[0] == '#' {
+            continue
+        }
+
+        // section: [name] or [parent.child]
+        if text[0] == '[' {
+            end := strings.IndexByte(text, ']')
+            if end < 0 {
+                return nil, fmt.Errorf("ini: line %d: invalid section", line)
+            }
+
+            section = root
+            for _, k := range strings.Split(text[1:end], ".") {
+                k = strings.TrimSpace(k)
+                sub, ok := section[k].(map[string]interface{})
+                if !ok {
+                    sub = make(map[string]interface{})
+                    section[k] = sub
+                }
+                section = sub
+            }
+            continue
+        }
+
+        pos := strings.IndexAny(text, "=:")
+        if pos <= 0 {
+            return nil, fmt.Errorf("ini: line %d: expect key = value", line)
+        }
+
+        key := strings.TrimSpace(text[:pos])
+        val, e := iniValue(strings.TrimSpace(text[pos+1:]))
+        if e != nil {
+            return nil, fmt.Errorf("ini: line %d: %s", line, e)
+        }
+
+        if strings.HasSuffix(key, "[]") {
+            key = strings.TrimSpace(key[:len(key)-2])
+            list, _ := section[key].([]interface{})
+            section[key] = append(list, val)
+        } else {
+            section[key] = val
+        }
+    }
+
+    if e := scanner.Err(); e != nil {
+        return nil, fmt.Errorf("ini: %s", e)
+    }
+
+    return root, nil
+}
+
+// iniValue decode quoted string, or unquoted value with inline comment
+func iniValue(s string) (interface{}, error) {
+    if len(s) >= 2 && s[0] == '"' {
+        end := strings.LastIndexByte(s, '"')
+        return strconv.Unquote(s[:end+1])
+    }
+
+    if len(s) >= 2 && s[0] == '\'' {
+        end := strings.LastIndexByte(s, '\'')
+        if end <= 0 {
+            return nil, fmt.Errorf("unterminated string")
+        }
+
+        return s[1:end], nil
+    }
+
+    // strip inline comment
+    if pos := strings.Index(s, " ;"); pos >= 0 {
+        s = strings.TrimSpace(s[:pos])
+    }
+
+    if pos := strings.Index(s, " #"); pos >= 0 {
+        s = strings.TrimSpace(s[:pos])
+    }
+
+    switch strings.ToLower(s) {
+    case "true", "on", "yes":
+        return true, nil
+    case "false", "off", "no":
+        return false, nil
+    }
+
+    if n, e := strconv.ParseInt(s, 10, 64); e == nil {
+        return int(n), nil
+    } else if f, e := strconv.ParseFloat(s, 64); e == nil {
+        return f, nil
+    }
+
+    return s, nil
+}
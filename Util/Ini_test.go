@@ -0,0 +1,67 @@
+package Util
+
+import (
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestIniUnmarshal(t *testing.T) {
+    tests := []struct {
+        name string
+        doc  string
+        want M
+    }{
+        {"empty", "", M{}},
+        {"comments", "; comment\n# comment\n\n  ; indented\n", M{}},
+        {"separators", "a = 1\nb: 2\nc=3\nd = x = y\n", M{"a": 1, "b": 2, "c": 3, "d": "x = y"}},
+        {"empty value", "a =\n", M{"a": ""}},
+        {"sections", "top = 1\n[a]\nx = 1\n[b]\ny = 2\n", M{"top": 1, "a": M{"x": 1}, "b": M{"y": 2}}},
+        {"nested sections", "[a.b]\nx = 1\n[ a . c ]\ny = 2\n", M{"a": M{"b": M{"x": 1}, "c": M{"y": 2}}}},
+        {"reopen section", "[a]\nx = 1\n[b]\n[a]\ny = 2\n", M{"a": M{"x": 1, "y": 2}, "b": M{}}},
+        {"bools", "a = true\nb = On\nc = yes\nd = FALSE\ne = off\nf = no\n",
+            M{"a": true, "b": true, "c": true, "d": false, "e": false, "f": false}},
+        {"numbers", "a = 42\nb = -7\nc = 1.5\nd = 2e3\n", M{"a": 42, "b": -7, "c": 1.5, "d": 2e3}},
+        {"strings", "a = hello world\nb = 1.2.3\nc = http://localhost:8080\n",
+            M{"a": "hello world", "b": "1.2.3", "c": "http://localhost:8080"}},
+        {"double quoted", "a = \"a;b # c\"\nb = \"tab\\tnew\\n\"\nc = \"42\"\n", M{"a": "a;b # c", "b": "tab\tnew\n", "c": "42"}},
+        {"single quoted", "a = 'raw\\n'\nb = 'on'\n", M{"a": "raw\\n", "b": "on"}},
+        {"quoted with comment", "a = \"x\" ; comment\nb = 'y' # comment\n", M{"a": "x", "b": "y"}},
+        {"inline comments", "a = demo ; comment\nb = 8080 # comment\nc = a;b\nd = a#b\n", M{"a": "demo", "b": 8080, "c": "a;b", "d": "a#b"}},
+        {"lists", "[a]\nh[] = h1\nh[] = 2\nh [] = true\n", M{"a": M{"h": A{"h1", 2, true}}}},
+        {"crlf", "[a]\r\nx = 1\r\n", M{"a": M{"x": 1}}},
+    }
+
+    for _, tt := range tests {
+        got, e := IniUnmarshal([]byte(tt.doc))
+        if e != nil {
+            t.Errorf("%s: unexpected error: %s", tt.name, e)
+        } else if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestIniUnmarshalError(t *testing.T) {
+    tests := []struct {
+        name string
+        doc  string
+        err  string
+    }{
+        {"invalid section", "[a\nx = 1\n", "ini: line 1: invalid section"},
+        {"missing separator", "a = 1\nb\n", "ini: line 2: expect key = value"},
+        {"missing key", "= 1\n", "ini: line 1: expect key = value"},
+        {"unterminated double quote", "a = \"abc\n", "ini: line 1:"},
+        {"invalid escape", "a = \"\\q\"\n", "ini: line 1:"},
+        {"unterminated single quote", "a = 'abc\n", "ini: line 1: unterminated string"},
+    }
+
+    for _, tt := range tests {
+        _, e := IniUnmarshal([]byte(tt.doc))
+        if e == nil {
+            t.Errorf("%s: expect error", tt.name)
+        } else if !strings.Contains(e.Error(), tt.err) {
+            t.Errorf("%s: got error %q, want %q", tt.name, e, tt.err)
+        }
+    }
+}
@@ -156,6 +156,37 @@ func PanicTrace(maxDepth int, multiLine bool) string {
     return strings.Join(sources, ",")
 }
 
+// CallerTrace get stack trace of current goroutine, skip is the number
+// of frames to skip, with 0 identifying the caller of CallerTrace.
+func CallerTrace(skip, maxDepth int, multiLine bool) string {
+    pcs := make([]uintptr, maxDepth+skip+16)
+    n := runtime.Callers(skip+2, pcs)
+    frames := runtime.CallersFrames(pcs[:n])
+    sources := make([]string, 0, maxDepth)
+
+    for len(sources) < maxDepth {
+        frame, more := frames.Next()
+        if len(frame.File) > 0 && !strings.HasPrefix(frame.File, runtime.GOROOT()) {
+            file := frame.File
+            if pos := strings.LastIndex(file, "/src/"); pos >= 0 {
+                file = file[pos+5:]
+            }
+
+            sources = append(sources, fmt.Sprintf("%s:%d", file, frame.Line))
+        }
+
+        if !more {
+            break
+        }
+    }
+
+    if multiLine {
+        return strings.Join(sources, "\n")
+    }
+
+    return strings.Join(sources, ",")
+}
+
 // FormatVersion format version to have minimum depth,
 // eg. FormatVersion("v10...2....2.1-alpha", 5) == "v10.2.2.1.0-alpha"
 func FormatVersion(ver string, minDepth int) string {
@@ -0,0 +1,471 @@
+package Util
+
+import (
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+// TomlUnmarshal parse toml document to map, integers are decoded
+// as int, floats as float64, datetime values are kept as string.
+func TomlUnmarshal(data []byte) (out map[string]interface{}, err error) {
+    p := &tomlParser{src: []rune(string(data)), line: 1}
+
+    defer func() {
+        if v := recover(); v != nil {
+            if e, ok := v.(tomlError); ok {
+                out, err = nil, e
+            } else {
+                panic(v)
+            }
+        }
+    }()
+
+    return p.parse(), nil
+}
+
+type tomlError struct {
+    line int
+    msg  string
+}
+
+func (e tomlError) Error() string {
+    return fmt.Sprintf("toml: line %d: %s", e.line, e.msg)
+}
+
+type tomlParser struct {
+    src  []rune
+    pos  int
+    line int
+}
+
+func (p *tomlParser) fail(format string, args ...interface{}) {
+    panic(tomlError{p.line, fmt.Sprintf(format, args...)})
+}
+
+func (p *tomlParser) eof() bool {
+    return p.pos >= len(p.src)
+}
+
+func (p *tomlParser) peek(offset int) rune {
+    if p.pos+offset < len(p.src) {
+        return p.src[p.pos+offset]
+    }
+
+    return 0
+}
+
+func (p *tomlParser) next() rune {
+    if p.eof() {
+        p.fail("unexpected end of document")
+    }
+
+    c := p.src[p.pos]
+    if c == '\n' {
+        p.line++
+    }
+
+    p.pos++
+    return c
+}
+
+func (p *tomlParser) expect(c rune) {
+    if p.eof() || p.src[p.pos] != c {
+        p.fail("expect %q", c)
+    }
+
+    p.next()
+}
+
+func (p *tomlParser) hasPrefix(s string) bool {
+    for i, c := range []rune(s) {
+        if p.peek(i) != c {
+            return false
+        }
+    }
+
+    return true
+}
+
+// skip spaces and tabs
+func (p *tomlParser) skipSpace() {
+    for c := p.peek(0); c == ' ' || c == '\t'; c = p.peek(0) {
+        p.next()
+    }
+}
+
+// skip spaces, newlines and comments
+func (p *tomlParser) skipBlank() {
+    for !p.eof() {
+        switch p.peek(0) {
+        case ' ', '\t', '\r', '\n':
+            p.next()
+        case '#':
+            p.skipComment()
+        default:
+            return
+        }
+    }
+}
+
+func (p *tomlParser) skipComment() {
+    for !p.eof() && p.peek(0) != '\n' {
+        p.next()
+    }
+}
+
+// expect end of line after an expression
+func (p *tomlParser) endLine() {
+    p.skipSpace()
+    if p.peek(0) == '#' {
+        p.skipComment()
+    }
+
+    if p.peek(0) == '\r' {
+        p.next()
+    }
+
+    if !p.eof() {
+        p.expect('\n')
+    }
+}
+
+func (p *tomlParser) parse() map[string]interface{} {
+    root := make(map[string]interface{})
+    current := root
+
+    for p.skipBlank(); !p.eof(); p.skipBlank() {
+        if p.hasPrefix("[[") {
+            p.pos += 2
+            keys := p.parseKeys()
+            p.expect(']')
+            p.expect(']')
+            current = p.arrayTable(root, keys)
+        } else if p.peek(0) == '[' {
+            p.next()
+            keys := p.parseKeys()
+            p.expect(']')
+            current = p.table(root, keys)
+        } else {
+            p.parseKeyValue(current)
+        }
+
+        p.endLine()
+    }
+
+    return root
+}
+
+// parse dotted keys, eg. a."b.c".d
+func (p *tomlParser) parseKeys() []string {
+    keys := make([]string, 0)
+    for {
+        p.skipSpace()
+        switch c := p.peek(0); {
+        case c == '"':
+            keys = append(keys, p.parseBasicString())
+        case c == '\'':
+            keys = append(keys, p.parseLiteralString())
+        default:
+            start := p.pos
+            for c := p.peek(0); c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'; c = p.peek(0) {
+                p.next()
+            }
+
+            if start == p.pos {
+                p.fail("invalid key")
+            }
+
+            keys = append(keys, string(p.src[start:p.pos]))
+        }
+
+        p.skipSpace()
+        if p.peek(0) != '.' {
+            return keys
+        }
+
+        p.next()
+    }
+}
+
+// table get or create table of keys under m
+func (p *tomlParser) table(m map[string]interface{}, keys []string) map[string]interface{} {
+    for _, k := range keys {
+        switch v := m[k].(type) {
+        case nil:
+            t := make(map[string]interface{})
+            m[k], m = t, t
+        case map[string]interface{}:
+            m = v
+        case []interface{}:
+            // last table of array of tables
+            t, ok := v[len(v)-1].(map[string]interface{})
+            if !ok {
+                p.fail("key %s is not a table", k)
+            }
+            m = t
+        default:
+            p.fail("key %s is not a table", k)
+        }
+    }
+
+    return m
+}
+
+// arrayTable append a new table to array of tables
+func (p *tomlParser) arrayTable(root map[string]interface{}, keys []string) map[string]interface{} {
+    m, last := p.table(root, keys[:len(keys)-1]), keys[len(keys)-1]
+    arr, ok := m[last].([]interface{})
+    if !ok && m[last] != nil {
+        p.fail("key %s is not an array of tables", last)
+    }
+
+    t := make(map[string]interface{})
+    m[last] = append(arr, t)
+    return t
+}
+
+func (p *tomlParser) parseKeyValue(m map[string]interface{}) {
+    keys := p.parseKeys()
+    p.expect('=')
+    p.skipSpace()
+
+    m = p.table(m, keys[:len(keys)-1])
+    last := keys[len(keys)-1]
+    if _, ok := m[last]; ok {
+        p.fail("duplicate key %s", last)
+    }
+
+    m[last] = p.parseValue()
+}
+
+func (p *tomlParser) parseValue() interface{} {
+    switch c := p.peek(0); {
+    case p.hasPrefix(`"""`):
+        return p.parseMultiLineString('"')
+    case p.hasPrefix(`'''`):
+        return p.parseMultiLineString('\'')
+    case c == '"':
+        return p.parseBasicString()
+    case c == '\'':
+        return p.parseLiteralString()
+    case c == '[':
+        return p.parseArray()
+    case c == '{':
+        return p.parseInlineTable()
+    case p.hasPrefix("true"):
+        p.pos += 4
+        return true
+    case p.hasPrefix("false"):
+        p.pos += 5
+        return false
+    default:
+        return p.parseScalar()
+    }
+}
+
+func (p *tomlParser) parseArray() []interface{} {
+    p.expect('[')
+    arr := make([]interface{}, 0)
+    for {
+        p.skipBlank()
+        if p.peek(0) == ']' {
+            break
+        }
+
+        arr = append(arr, p.parseValue())
+        p.skipBlank()
+        if p.peek(0) == ',' {
+            p.next()
+        } else if p.peek(0) != ']' {
+            p.fail("expect ',' or ']' in array")
+        }
+    }
+
+    p.expect(']')
+    return arr
+}
+
+func (p *tomlParser) parseInlineTable() map[string]interface{} {
+    p.expect('{')
+    m := make(map[string]interface{})
+    for {
+        p.skipSpace()
+        if p.peek(0) == '}' {
+            break
+        }
+
+        p.parseKeyValue(m)
+        p.skipSpace()
+        if p.peek(0) == ',' {
+            p.next()
+        } else if p.peek(0) != '}' {
+            p.fail("expect ',' or '}' in inline table")
+        }
+    }
+
+    p.expect('}')
+    return m
+}
+
+func (p *tomlParser) parseLiteralString() string {
+    p.expect('\'')
+    start := p.pos
+    for p.peek(0) != '\'' {
+        if c := p.next(); c == '\n' {
+            p.fail("newline in string")
+        }
+    }
+
+    s := string(p.src[start:p.pos])
+    p.next()
+    return s
+}
+
+func (p *tomlParser) parseBasicString() string {
+    p.expect('"')
+    var b strings.Builder
+    for {
+        switch c := p.next(); c {
+        case '"':
+            return b.String()
+        case '\n':
+            p.fail("newline in string")
+        case '\\':
+            b.WriteRune(p.parseEscape())
+        default:
+            b.WriteRune(c)
+        }
+    }
+}
+
+func (p *tomlParser) parseMultiLineString(quote rune) string {
+    p.pos += 3
+
+    // newline immediately following opening delimiter is trimmed
+    if p.hasPrefix("\r\n") {
+        p.pos++
+    }
+
+    if p.peek(0) == '\n' {
+        p.next()
+    }
+
+    var b strings.Builder
+    for {
+        if p.peek(0) == quote && p.peek(1) == quote && p.peek(2) == quote {
+            // at most two quotes can be adjacent to closing delimiter
+            for p.peek(3) == quote {
+                b.WriteRune(p.next())
+            }
+
+            p.pos += 3
+            return b.String()
+        }
+
+        c := p.next()
+        if c != '\\' || quote == '\'' {
+            b.WriteRune(c)
+            continue
+        }
+
+        // line ending backslash trims whitespaces and newlines
+        if n := p.peek(0); n == ' ' || n == '\t' || n == '\r' || n == '\n' {
+            for n := p.peek(0); n == ' ' || n == '\t' || n == '\r' || n == '\n'; n = p.peek(0) {
+                p.next()
+            }
+            continue
+        }
+
+        b.WriteRune(p.parseEscape())
+    }
+}
+
+func (p *tomlParser) parseEscape() rune {
+    switch c := p.next(); c {
+    case 'b':
+        return '\b'
+    case 't':
+        return '\t'
+    case 'n':
+        return '\n'
+    case 'f':
+        return '\f'
+    case 'r':
+        return '\r'
+    case '"':
+        return '"'
+    case '\\':
+        return '\\'
+    case 'u', 'U':
+        size := 4
+        if c == 'U' {
+            size = 8
+        }
+
+        if p.pos+size > len(p.src) {
+            p.fail("invalid unicode escape")
+        }
+
+        n, e := strconv.ParseUint(string(p.src[p.pos:p.pos+size]), 16, 32)
+        if e != nil || !utf8.ValidRune(rune(n)) {
+            p.fail("invalid unicode escape")
+        }
+
+        p.pos += size
+        return rune(n)
+    default:
+        p.fail("invalid escape \\%c", c)
+        return 0
+    }
+}
+
+// parse number or datetime
+func (p *tomlParser) parseScalar() interface{} {
+    start := p.pos
+    for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", p.peek(0)) {
+        p.next()
+    }
+
+    // datetime with space delimiter, eg. 1979-05-27 07:32:00
+    if p.pos-start == 10 && p.src[start+4] == '-' && p.peek(0) == ' ' && p.peek(1) >= '0' && p.peek(1) <= '9' {
+        p.next()
+        for !p.eof() && !strings.ContainsRune(" \t\r\n,]}#", p.peek(0)) {
+            p.next()
+        }
+    }
+
+    token := string(p.src[start:p.pos])
+    switch strings.TrimLeft(token, "+-") {
+    case "":
+        p.fail("missing value")
+    case "inf":
+        if token[0] == '-' {
+            return math.Inf(-1)
+        }
+        return math.Inf(1)
+    case "nan":
+        return math.NaN()
+    }
+
+    num := strings.Replace(token, "_", "", -1)
+    if len(num) > 2 && num[0] == '0' && strings.ContainsRune("xob", rune(num[1])) {
+        base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[num[1]]
+        if n, e := strconv.ParseInt(num[2:], base, 64); e == nil {
+            return int(n)
+        }
+    } else if n, e := strconv.ParseInt(num, 10, 64); e == nil {
+        return int(n)
+    } else if strings.ContainsAny(num, ".eE") && !strings.ContainsAny(num, ":") {
+        if f, e := strconv.ParseFloat(num, 64); e == nil {
+            return f
+        }
+    } else if strings.ContainsAny(token, "-:") && token[0] >= '0' && token[0] <= '9' {
+        return token // datetime
+    }
+
+    p.fail("invalid value %s", token)
+    return nil
+}
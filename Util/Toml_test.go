@@ -0,0 +1,135 @@
+package Util
+
+import (
+    "math"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+type M = map[string]interface{}
+type A = []interface{}
+
+func TestTomlUnmarshal(t *testing.T) {
+    tests := []struct {
+        name string
+        doc  string
+        want M
+    }{
+        {"empty", "", M{}},
+        {"comments", "# comment\n\n  # indented\na = 1 # trailing\n", M{"a": 1}},
+        {"crlf", "a = 1\r\nb = 2\r\n", M{"a": 1, "b": 2}},
+        {"bare keys", "key = 1\nbare_key = 2\nbare-key = 3\n1234 = 4\n", M{"key": 1, "bare_key": 2, "bare-key": 3, "1234": 4}},
+        {"quoted keys", "\"a.b\" = 1\n'c d' = 2\n\"\" = 3\n", M{"a.b": 1, "c d": 2, "": 3}},
+        {"dotted keys", "a.b.c = 1\na . \"b.c\" = 2\n", M{"a": M{"b": M{"c": 1}, "b.c": 2}}},
+
+        // strings
+        {"basic string", `s = "hello world"`, M{"s": "hello world"}},
+        {"escapes", `s = "b\b t\t n\n f\f r\r q\" bs\\"`, M{"s": "b\b t\t n\n f\f r\r q\" bs\\"}},
+        {"unicode escapes", `s = "\u00e9 \U0001F600"`, M{"s": "é 😀"}},
+        {"literal string", `s = 'C:\Users\nodejs'`, M{"s": `C:\Users\nodejs`}},
+        {"multiline basic", "s = \"\"\"\nRoses are red\nViolets are blue\"\"\"", M{"s": "Roses are red\nViolets are blue"}},
+        {"multiline crlf trim", "s = \"\"\"\r\nline\"\"\"", M{"s": "line"}},
+        {"line ending backslash", "s = \"\"\"\nThe quick \\\n\n   brown fox\"\"\"", M{"s": "The quick brown fox"}},
+        {"multiline escapes", "s = \"\"\"a\\tb\\u00e9\"\"\"", M{"s": "a\tbé"}},
+        {"multiline quotes", "s = \"\"\"\"quoted\"\"\"\"\"", M{"s": "\"quoted\"\""}},
+        {"multiline literal", "s = '''\nraw \\n text\n'''", M{"s": "raw \\n text\n"}},
+        {"multiline literal quotes", "s = ''''one'''", M{"s": "'one"}},
+
+        // numbers
+        {"integers", "a = 99\nb = +17\nc = -17\nd = 0\ne = 1_000\n", M{"a": 99, "b": 17, "c": -17, "d": 0, "e": 1000}},
+        {"prefixed integers", "h = 0xDEAD_beef\no = 0o755\nb = 0b1101\n", M{"h": 0xDEADBEEF, "o": 0755, "b": 13}},
+        {"floats", "a = 3.14\nb = -0.01\nc = 5e+22\nd = 1e06\ne = -2E-2\nf = 6.626e-34\ng = 224_617.445_991\n",
+            M{"a": 3.14, "b": -0.01, "c": 5e+22, "d": 1e06, "e": -2e-2, "f": 6.626e-34, "g": 224617.445991}},
+        {"infinity", "a = inf\nb = +inf\nc = -inf\n", M{"a": math.Inf(1), "b": math.Inf(1), "c": math.Inf(-1)}},
+
+        // bool and datetime
+        {"bools", "t = true\nf = false\n", M{"t": true, "f": false}},
+        {"datetimes", "a = 1979-05-27T07:32:00Z\nb = 1979-05-27T00:32:00.999999-07:00\nc = 1979-05-27 07:32:00\nd = 1979-05-27\ne = 07:32:00\n",
+            M{"a": "1979-05-27T07:32:00Z", "b": "1979-05-27T00:32:00.999999-07:00", "c": "1979-05-27 07:32:00", "d": "1979-05-27", "e": "07:32:00"}},
+        {"datetime before comment", "d = 1979-05-27 # date\n", M{"d": "1979-05-27"}},
+
+        // arrays
+        {"arrays", "a = [1, 2, 3]\nb = [\"a\", 'b']\nc = [[1, 2], [\"x\"]]\nd = []\n",
+            M{"a": A{1, 2, 3}, "b": A{"a", "b"}, "c": A{A{1, 2}, A{"x"}}, "d": A{}}},
+        {"multiline array", "a = [\n  1, # one\n  2,\n]\n", M{"a": A{1, 2}}},
+        {"mixed array", "a = [1, 'a', {b = 2}]", M{"a": A{1, "a", M{"b": 2}}}},
+
+        // tables
+        {"tables", "[a]\nx = 1\n[b.c]\ny = 2\n", M{"a": M{"x": 1}, "b": M{"c": M{"y": 2}}}},
+        {"table header spaces", "[ a . \"b\" ]\nx = 1\n", M{"a": M{"b": M{"x": 1}}}},
+        {"super table after sub table", "[a.b]\nx = 1\n[a]\ny = 2\n", M{"a": M{"b": M{"x": 1}, "y": 2}}},
+        {"inline tables", "p = {x = 1, y = 'a'}\nq = {}\nr = {a.b = true}\n",
+            M{"p": M{"x": 1, "y": "a"}, "q": M{}, "r": M{"a": M{"b": true}}}},
+
+        // array of tables
+        {"array of tables", "[[p]]\nname = 'a'\n[[p]]\n[[p]]\nname = 'c'\n",
+            M{"p": A{M{"name": "a"}, M{}, M{"name": "c"}}}},
+        {"nested array of tables", "[[f]]\nname = 'apple'\n[f.physical]\ncolor = 'red'\n[[f.variety]]\nname = 'x'\n[[f.variety]]\nname = 'y'\n[[f]]\nname = 'banana'\n",
+            M{"f": A{
+                M{"name": "apple", "physical": M{"color": "red"}, "variety": A{M{"name": "x"}, M{"name": "y"}}},
+                M{"name": "banana"},
+            }}},
+    }
+
+    for _, tt := range tests {
+        got, e := TomlUnmarshal([]byte(tt.doc))
+        if e != nil {
+            t.Errorf("%s: unexpected error: %s", tt.name, e)
+        } else if !reflect.DeepEqual(got, tt.want) {
+            t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+        }
+    }
+}
+
+func TestTomlUnmarshalNaN(t *testing.T) {
+    got, e := TomlUnmarshal([]byte("a = nan\nb = -nan\n"))
+    if e != nil {
+        t.Fatal(e)
+    }
+
+    for _, k := range []string{"a", "b"} {
+        if f, ok := got[k].(float64); !ok || !math.IsNaN(f) {
+            t.Errorf("%s: got %#v, want NaN", k, got[k])
+        }
+    }
+}
+
+func TestTomlUnmarshalError(t *testing.T) {
+    tests := []struct {
+        name string
+        doc  string
+        err  string
+    }{
+        {"missing value", "a =\n", "line 1: missing value"},
+        {"missing equal", "a 1\n", "expect '='"},
+        {"invalid key", "= 1\n", "invalid key"},
+        {"duplicate key", "a = 1\na = 2\n", "line 2: duplicate key a"},
+        {"duplicate dotted key", "a.b = 1\na.b = 2\n", "duplicate key b"},
+        {"two values on a line", "a = 1 b = 2\n", "expect '\\n'"},
+        {"newline in string", "a = \"x\ny\"\n", "newline in string"},
+        {"newline in literal", "a = 'x\ny'\n", "newline in string"},
+        {"unterminated string", "a = \"abc", "unexpected end of document"},
+        {"unterminated multiline", "a = \"\"\"abc\n", "unexpected end of document"},
+        {"invalid escape", `a = "\x41"`, `invalid escape \x`},
+        {"invalid unicode", `a = "\uZZZZ"`, "invalid unicode escape"},
+        {"invalid surrogate", `a = "\uD800"`, "invalid unicode escape"},
+        {"invalid number", "a = 12abc\n", "invalid value 12abc"},
+        {"unclosed array", "a = [1, 2\n", "expect ',' or ']' in array"},
+        {"array missing comma", "a = [1 2]\n", "expect ',' or ']' in array"},
+        {"inline table newline", "a = {x = 1\n}\n", "expect ',' or '}' in inline table"},
+        {"unclosed table", "[a\nx = 1\n", "expect ']'"},
+        {"table over value", "a = 1\n[a]\n", "key a is not a table"},
+        {"dotted key over value", "a = 1\na.b = 2\n", "key a is not a table"},
+        {"array table over table", "[a]\n[[a]]\n", "key a is not an array of tables"},
+    }
+
+    for _, tt := range tests {
+        _, e := TomlUnmarshal([]byte(tt.doc))
+        if e == nil {
+            t.Errorf("%s: expect error", tt.name)
+        } else if !strings.Contains(e.Error(), tt.err) {
+            t.Errorf("%s: got error %q, want %q", tt.name, e, tt.err)
+        }
+    }
+}